@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+	"github.com/urfave/cli"
+)
+
+// inspectVersion version of the inspect JSON format, it is increased on incompatible changes.
+const inspectVersion = 1
+
+// inspectOutput inspect JSON document.
+type inspectOutput struct {
+	Version  int             `json:"version"`
+	Services []parser.Result `json:"services"`
+	Config   inspectConfig   `json:"config"`
+}
+
+// inspectConfig config with the command line flags and defaults applied.
+type inspectConfig struct {
+	ConfigFile  string                       `json:"configFile"`
+	Service     string                       `json:"service,omitempty"`
+	Discover    bool                         `json:"discover,omitempty"`
+	Path        string                       `json:"path"`
+	OutputDir   string                       `json:"outputDir"`
+	TemplateDir string                       `json:"templateDir"`
+	Files       map[string]string            `json:"files,omitempty"`
+	HTTP        config.HTTPTransport         `json:"http"`
+	Endpoint    config.Endpoint              `json:"endpoint"`
+	JSON        config.JSON                  `json:"json"`
+	Resilience  config.Resilience            `json:"resilience"`
+	Validate    map[string]map[string]string `json:"validate,omitempty"`
+	Generators  map[string]map[string]bool   `json:"generators,omitempty"`
+	Generate    []config.Generate            `json:"generate,omitempty"`
+	// Plugins plugin paths by name.
+	Plugins map[string]string `json:"plugins,omitempty"`
+}
+
+// inspectCommand prints the parsed services and the config as JSON or a table.
+func inspectCommand(c *cli.Context) error {
+	s := svc(c)
+	switch format := c.String("format"); format {
+	case "json":
+		return inspectJSON(os.Stdout, s)
+	case "table":
+		return inspectTable(os.Stdout, s)
+	default:
+		return fmt.Errorf("unknown format %q, use json or table", format)
+	}
+}
+
+func inspectJSON(w io.Writer, s *service) error {
+	out := inspectOutput{
+		Version:  inspectVersion,
+		Services: s.results,
+		Config: inspectConfig{
+			ConfigFile:  s.configFile,
+			Service:     s.cfg.Service,
+			Discover:    s.cfg.Discover,
+			Path:        s.path,
+			OutputDir:   s.outputDir(),
+			TemplateDir: s.templateDir(),
+			Files:       s.cfg.Output.Files,
+			HTTP:        s.http,
+			Endpoint:    s.cfg.Endpoint,
+			JSON:        s.cfg.JSON,
+			Resilience:  s.cfg.Resilience,
+			Validate:    s.cfg.Validate,
+			Generators:  s.cfg.Generators,
+			Generate:    s.cfg.Generate,
+		},
+	}
+	if out.Services == nil {
+		out.Services = []parser.Result{}
+	}
+	for name, p := range s.cfg.Plugins {
+		if out.Config.Plugins == nil {
+			out.Config.Plugins = map[string]string{}
+		}
+		out.Config.Plugins[name] = p.Path
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// inspectTable prints a row per method param and result of every service.
+func inspectTable(w io.Writer, s *service) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tMETHOD\tKIND\tNAME\tTYPE\tIMPORTS")
+	for _, r := range s.results {
+		service := r.Pkg + "." + r.ServiceName
+		if r.Name != r.ServiceName {
+			service += " (" + r.Name + ")"
+		}
+		for _, m := range r.Methods {
+			if len(m.Params) == 0 && len(m.Results) == 0 {
+				fmt.Fprintf(tw, "%s\t%s\t-\t\t\t\n", service, m.Name)
+			}
+			for _, f := range m.Params {
+				fmt.Fprintf(tw, "%s\t%s\tparam\t%s\t%s\t%s\n", service, m.Name, f.Name, fieldType(f), fieldImports(f))
+			}
+			for _, f := range m.Results {
+				fmt.Fprintf(tw, "%s\t%s\tresult\t%s\t%s\t%s\n", service, m.Name, f.Name, fieldType(f), fieldImports(f))
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+// fieldType returns the field type as declared, variadic params as ...T.
+func fieldType(f parser.Field) string {
+	if f.Variadic {
+		return "..." + strings.TrimPrefix(f.Type, "[]")
+	}
+	return f.Type
+}
+
+// fieldImports returns the packages the field type refers to as name=path.
+func fieldImports(f parser.Field) string {
+	var imports []string
+	for name, path := range f.Imports {
+		imports = append(imports, name+"="+path)
+	}
+	sort.Strings(imports)
+	return strings.Join(imports, " ")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// inspectService returns the service of a config with every inspected section set.
+func inspectService() *service {
+	ctx := parser.Field{Name: "ctx", Type: "context.Context", Imports: map[string]string{"context": "context"}}
+	return &service{
+		configFile: filepath.Join("hello", ".gokit.yaml"),
+		path:       "hello",
+		cfg: config.Config{
+			Service:    "hello.Service",
+			Endpoint:   config.Endpoint{Errors: "endpoint"},
+			Validate:   map[string]map[string]string{"Say": {"name": "required maxlen=64"}},
+			Output:     config.Output{Files: map[string]string{"http_gen.go": "transport_gen.go"}},
+			Plugins:    map[string]config.Plugin{"lint": {Path: "/usr/local/bin/gokitgen-lint", Options: map[string]interface{}{"strict": true}}},
+			Generators: map[string]map[string]bool{"http": {"c": true}},
+			Generate:   []config.Generate{{Generator: "http"}, {Generator: "template", Args: []string{"docs"}}},
+		},
+		http: config.HTTPTransport{
+			Router:    "mux",
+			Endpoints: map[string]config.HTTPEndpoint{"Say": {Method: "get", Path: "/say/{name}"}},
+		},
+		results: []parser.Result{{
+			Pkg:         "hello",
+			Root:        "example.com/hello",
+			ServiceName: "Service",
+			Name:        "Service",
+			Methods: []parser.Method{
+				{
+					Name:    "Say",
+					Params:  []parser.Field{ctx, {Name: "names", Type: "[]string", Variadic: true}},
+					Results: []parser.Field{{Name: "message", Type: "string"}, {Name: "err", Type: "error"}},
+				},
+				{Name: "Ping"},
+			},
+		}},
+	}
+}
+
+// TestInspectJSON compares the inspect document with the golden file,
+// run the tests with -update to update it after a compatible change.
+func TestInspectJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := inspectJSON(&buf, inspectService()); err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "inspect.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(want) {
+		t.Errorf("the inspect document differs from %s, increase inspectVersion on incompatible changes:\n%s", golden, buf.String())
+	}
+
+	var doc struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(want, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != inspectVersion {
+		t.Errorf("%s: version %d, want %d", golden, doc.Version, inspectVersion)
+	}
+}
+
+func TestInspectJSONNoServices(t *testing.T) {
+	var buf bytes.Buffer
+	if err := inspectJSON(&buf, &service{configFile: ".gokit.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"services": [],`) {
+		t.Errorf("services are not an empty list:\n%s", buf.String())
+	}
+}
+
+func TestInspectTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := inspectTable(&buf, inspectService()); err != nil {
+		t.Fatal(err)
+	}
+	want := `SERVICE        METHOD  KIND    NAME     TYPE             IMPORTS
+hello.Service  Say     param   ctx      context.Context  context=context
+hello.Service  Say     param   names    ...string
+hello.Service  Say     result  message  string
+hello.Service  Say     result  err      error
+hello.Service  Ping    -
+`
+	// cells are padded up to the last column, trailing spaces are not compared
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	if got := strings.Join(lines, "\n"); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
-	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
+	"github.com/l-vitaly/gokitgen/pkg/config"
 	"github.com/l-vitaly/gokitgen/pkg/generators"
+	"github.com/l-vitaly/gokitgen/pkg/output"
 	"github.com/l-vitaly/gokitgen/pkg/parser"
 	"github.com/urfave/cli"
 )
@@ -22,115 +25,95 @@ func main() {
 		cli.StringFlag{
 			Name: "p",
 		},
+		cli.StringFlag{
+			Name:  "c",
+			Value: ".gokit.yaml",
+		},
+		cli.BoolFlag{
+			Name:  "discover",
+			Usage: "generate the interfaces marked by the " + parser.Directive + " directive if no service is set",
+		},
+		cli.StringFlag{
+			Name:  "templates",
+			Usage: "directory with templates overriding the built-in ones",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "directory generated files are written to, the service path by default",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "overwrite files not generated by gokitgen and replace edited code that is generated now",
+		},
+		cli.BoolFlag{
+			Name:  "no-typecheck",
+			Usage: "write generated files without type-checking the package",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print the files instead of writing them",
+		},
+		cli.BoolFlag{
+			Name:  "diff",
+			Usage: "print unified diffs against the files on disk instead of writing them",
+		},
+		cli.BoolFlag{
+			Name:  "check",
+			Usage: "exit with an error if any generated file is stale",
+		},
 	}
-	app.Before = func(c *cli.Context) error {
-		path, err := filepath.Abs(c.String("p"))
-		if err != nil {
-			return err
-		}
-		basePath, err := filepath.Abs(path)
-		if err != nil {
-			return err
-		}
-		result, err := new(parser.Parser).Parse(basePath, c.String("s"))
-		if err != nil {
-			return err
+	app.Before = load
+	app.After = func(c *cli.Context) error {
+		if s, ok := c.App.Metadata["service"].(*service); ok {
+			return s.w.Err()
 		}
-		c.App.Metadata["result"] = result
-		c.App.Metadata["path"] = path
 		return nil
 	}
 
-	app.Commands = []cli.Command{
-		{
-			Name:    "transport",
-			Aliases: []string{"t"},
-			Usage:   "",
-			Subcommands: []cli.Command{
-				{
-					Name: "http",
-					Flags: []cli.Flag{
-						cli.BoolFlag{
-							Name: "zipkin",
-						},
-						cli.BoolFlag{
-							Name: "logger",
-						},
-						cli.BoolFlag{
-							Name: "greq",
-						},
-						cli.BoolFlag{
-							Name: "gresp",
-						},
-						cli.BoolFlag{
-							Name: "c",
-						},
-					},
-					Action: func(c *cli.Context) error {
-						transportGenerator := generators.NewHTTPTransport(
-							generators.HTTPGeneratorZipkin(c.Bool("zipkin")),
-							generators.HTTPGeneratorClient(c.Bool("c")),
-							generators.HTTPGeneratorLogger(c.Bool("logger")),
-							generators.HTTPGeneratorGenericRequest(c.Bool("greq")),
-							generators.HTTPGeneratorGenericResponse(c.Bool("gresp")),
-						)
-						data, err := transportGenerator.Generate(c.App.Metadata["result"].(parser.Result))
-						if err != nil {
-							return err
-						}
-
-						savePath := c.App.Metadata["path"].(string)
-						if err := ioutil.WriteFile(savePath+"/http.go", data, 0755); err != nil {
-							return err
-						}
-
-						return nil
-					},
-				},
+	app.Commands = append(commands(), cli.Command{
+		Name:   "generate",
+		Usage:  "run the generators of the generate section of the config",
+		Action: generateCommand,
+	}, cli.Command{
+		Name:  "watch",
+		Usage: "run the generate command whenever the service interface or the config changes",
+		Flags: []cli.Flag{
+			cli.DurationFlag{
+				Name:  "interval",
+				Value: 500 * time.Millisecond,
+				Usage: "how often files are polled",
 			},
-		},
-		{
-			Name:    "endpoint",
-			Aliases: []string{"e"},
-			Usage:   "",
-			Action: func(c *cli.Context) error {
-				transportGenerator := generators.NewEndpoint()
-				data, err := transportGenerator.Generate(c.App.Metadata["result"].(parser.Result))
-				if err != nil {
-					return err
-				}
-				savePath := c.App.Metadata["path"].(string)
-				if err := ioutil.WriteFile(savePath+"/endpoints.go", data, 0755); err != nil {
-					return err
-				}
-				return nil
+			cli.DurationFlag{
+				Name:  "debounce",
+				Value: 300 * time.Millisecond,
+				Usage: "how long files must stay unchanged before regenerating",
 			},
 		},
-		{
-			Name:    "logging",
-			Aliases: []string{"lg"},
-			Usage:   "",
-			Flags: []cli.Flag{
-				cli.BoolFlag{
-					Name: "st",
-				},
+		Action: watchCommand,
+	}, cli.Command{
+		Name:      "services",
+		Usage:     "run the generate command of the services listed in the config or found under the dir",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "j",
+				Value: runtime.NumCPU(),
+				Usage: "number of services generated in parallel",
 			},
-			Action: func(c *cli.Context) error {
-				transportGenerator := generators.NewLogging(
-					generators.LoggingGeneratorEnableStackTrace(c.Bool("st")),
-				)
-				data, err := transportGenerator.Generate(c.App.Metadata["result"].(parser.Result))
-				if err != nil {
-					return err
-				}
-				savePath := c.App.Metadata["path"].(string)
-				if err := ioutil.WriteFile(savePath+"/logging.go", data, 0755); err != nil {
-					return err
-				}
-				return nil
+		},
+		Action: servicesCommand,
+	}, cli.Command{
+		Name:  "inspect",
+		Usage: "print the parsed services and the config",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "format",
+				Value: "json",
+				Usage: "output format, json or table",
 			},
 		},
-	}
+		Action: inspectCommand,
+	})
 
 	err := app.Run(os.Args)
 	if err != nil {
@@ -138,3 +121,128 @@ func main() {
 	}
 
 }
+
+// load loads the service of the run, flags override the config.
+func load(c *cli.Context) error {
+	s, err := loadService(c.String("c"), func(cfg *config.Config) {
+		if c.IsSet("s") || cfg.Service == "" {
+			cfg.Service = c.String("s")
+		}
+		if c.IsSet("p") || cfg.Path == "" {
+			cfg.Path = c.String("p")
+		}
+		if c.IsSet("discover") {
+			cfg.Discover = c.Bool("discover")
+		}
+		if c.IsSet("templates") {
+			cfg.Templates.Dir = c.String("templates")
+		}
+		if c.IsSet("out") {
+			cfg.Output.Dir = c.String("out")
+		}
+	}, newWriter(c))
+	if err != nil {
+		return err
+	}
+	c.App.Metadata["service"] = s
+	return nil
+}
+
+// newWriter creates an output writer configured by the global flags.
+func newWriter(c *cli.Context, options ...output.WriterOption) *output.Writer {
+	return output.NewWriter(append([]output.WriterOption{
+		output.WriterDryRun(c.GlobalBool("dry-run")),
+		output.WriterDiff(c.GlobalBool("diff")),
+		output.WriterCheck(c.GlobalBool("check")),
+		output.WriterForce(c.GlobalBool("force")),
+		output.WriterTypeCheck(!c.GlobalBool("no-typecheck")),
+	}, options...)...)
+}
+
+// groupAliases aliases of the commands grouping generator commands.
+var groupAliases = map[string][]string{
+	"transport": {"t"},
+}
+
+// commands builds the command tree of the registered generators.
+func commands() []cli.Command {
+	var cmds []cli.Command
+	for _, r := range generators.Registered() {
+		cmds = addCommand(cmds, r.Command, generatorCommand(r))
+	}
+	return cmds
+}
+
+// addCommand adds cmd at the path, group commands are created as needed.
+func addCommand(cmds []cli.Command, path []string, cmd cli.Command) []cli.Command {
+	if len(path) == 1 {
+		return append(cmds, cmd)
+	}
+	for i := range cmds {
+		if cmds[i].Name == path[0] {
+			cmds[i].Subcommands = addCommand(cmds[i].Subcommands, path[1:], cmd)
+			return cmds
+		}
+	}
+	return append(cmds, cli.Command{
+		Name:        path[0],
+		Aliases:     groupAliases[path[0]],
+		Subcommands: addCommand(nil, path[1:], cmd),
+	})
+}
+
+func generatorCommand(r generators.Registration) cli.Command {
+	var flags []cli.Flag
+	for _, f := range r.Flags {
+		flags = append(flags, cli.BoolFlag{Name: f.Name, Usage: f.Usage})
+	}
+	usage := r.Usage
+	if len(r.Requires) > 0 {
+		usage += ", the code uses the output of " + strings.Join(r.Requires, ", ")
+	}
+	return cli.Command{
+		Name:      r.Command[len(r.Command)-1],
+		Aliases:   r.Aliases,
+		Usage:     usage,
+		ArgsUsage: r.ArgsUsage,
+		Flags:     flags,
+		Action: func(c *cli.Context) error {
+			if err := runGenerator(c, r); err != nil {
+				return err
+			}
+			return writer(c).Flush()
+		},
+	}
+}
+
+// runGenerator runs the registered generator and stages its files, flags of the
+// command override the generator options of the config.
+func runGenerator(c *cli.Context, r generators.Registration) error {
+	flags := map[string]bool{}
+	for _, f := range r.Flags {
+		if c.IsSet(f.Name) {
+			flags[f.Name] = c.Bool(f.Name)
+		}
+	}
+	job := config.Generate{Generator: r.Name, Args: c.Args(), Flags: flags}
+	files, err := svc(c).generate(r, job)
+	if err != nil {
+		return err
+	}
+	return svc(c).stage(jobName(job), files)
+}
+
+// generateCommand runs the generators of the generate section of the config.
+func generateCommand(c *cli.Context) error {
+	return svc(c).generateAll()
+}
+
+// svc returns the service of the run.
+func svc(c *cli.Context) *service {
+	return c.App.Metadata["service"].(*service)
+}
+
+// writer returns the output writer of the command.
+func writer(c *cli.Context) *output.Writer {
+	return svc(c).w
+}
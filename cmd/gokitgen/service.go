@@ -0,0 +1,302 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/generators"
+	"github.com/l-vitaly/gokitgen/pkg/loader"
+	"github.com/l-vitaly/gokitgen/pkg/merge"
+	"github.com/l-vitaly/gokitgen/pkg/output"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+	"github.com/l-vitaly/gokitgen/pkg/utils"
+)
+
+// service service of a config file the generators run for.
+type service struct {
+	configFile string
+	cfg        config.Config
+	path       string
+	http       config.HTTPTransport
+	results    []parser.Result
+	w          *output.Writer
+	log        *log.Logger
+}
+
+// loadService loads the config file and parses the service, override changes the config before.
+// Without a service in the config the services marked by the //gokit:service directive are
+// parsed if discovery is enabled, generators fail to run if there are none.
+func loadService(configFile string, override func(cfg *config.Config), w *output.Writer) (*service, error) {
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		override(&cfg)
+	}
+	s := &service{
+		configFile: configFile,
+		cfg:        cfg,
+		w:          w,
+		log:        log.New(os.Stderr, "", log.LstdFlags),
+	}
+	if err := cfg.Transports.Unmarshal("http", &s.http); err != nil {
+		return nil, err
+	}
+	if s.path, err = filepath.Abs(cfg.Path); err != nil {
+		return nil, err
+	}
+	switch {
+	case cfg.Service != "":
+		result, err := new(parser.Parser).Parse(s.path, cfg.Service)
+		if err != nil {
+			return nil, err
+		}
+		s.results = []parser.Result{result}
+	case cfg.Discover:
+		if s.results, err = new(parser.Parser).Discover(s.path); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// loadConfig loads the config file if it exists, the service path is resolved relative to the file.
+func loadConfig(filename string) (config.Config, error) {
+	cfg := config.Config{}
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	resolver := loader.NewLoaderResolver()
+	resolver.Add(loader.NewYAML())
+
+	l := resolver.Resolve(filename)
+	if l == nil {
+		return cfg, fmt.Errorf("unsupported config file %s", filename)
+	}
+	l.SetConfig(&cfg)
+	if err := l.Load(filename); err != nil {
+		return cfg, err
+	}
+	if cfg.Path != "" && !filepath.IsAbs(cfg.Path) {
+		cfg.Path = filepath.Join(filepath.Dir(filename), cfg.Path)
+	}
+	if cfg.Templates.Dir != "" && !filepath.IsAbs(cfg.Templates.Dir) {
+		cfg.Templates.Dir = filepath.Join(filepath.Dir(filename), cfg.Templates.Dir)
+	}
+	if cfg.Output.Dir != "" && !filepath.IsAbs(cfg.Output.Dir) {
+		cfg.Output.Dir = filepath.Join(filepath.Dir(filename), cfg.Output.Dir)
+	}
+	for name, p := range cfg.Plugins {
+		// a bare name is looked up in PATH
+		if strings.ContainsRune(p.Path, filepath.Separator) && !filepath.IsAbs(p.Path) {
+			p.Path = filepath.Join(filepath.Dir(filename), p.Path)
+			cfg.Plugins[name] = p
+		}
+	}
+	for i, pattern := range cfg.Services {
+		if !filepath.IsAbs(pattern) {
+			cfg.Services[i] = filepath.Join(filepath.Dir(filename), pattern)
+		}
+	}
+	return cfg, nil
+}
+
+// generate runs the generator of the job for every service, job flags override the generators section
+// of the config. Files of services sharing a package are prefixed with the service names.
+func (s *service) generate(r generators.Registration, job config.Generate) ([]generators.File, error) {
+	if len(s.results) == 0 {
+		if s.cfg.Discover {
+			return nil, fmt.Errorf("no interfaces marked by %s in %s", parser.Directive, s.path)
+		}
+		return nil, errors.New("no service, set it with -s or in the config")
+	}
+	flags := map[string]bool{}
+	for name, v := range s.cfg.Generators[r.Name] {
+		flags[name] = v
+	}
+	for name, v := range job.Flags {
+		flags[name] = v
+	}
+
+	g, err := r.New(generators.Options{
+		Flags:       flags,
+		Args:        job.Args,
+		TemplateDir: s.templateDir(),
+		HTTP:        s.http,
+		Config:      s.cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var files []generators.File
+	for _, result := range s.results {
+		if r.Transport != "" && !result.HasTransport(r.Transport) {
+			continue
+		}
+		fs, err := g.Generate(result)
+		if err != nil {
+			if len(s.results) > 1 {
+				err = fmt.Errorf("%s: %v", result.Name, err)
+			}
+			return nil, err
+		}
+		for _, f := range fs {
+			if result.Prefix != "" {
+				f.Name = filepath.Join(filepath.Dir(f.Name), utils.SnakeCase(result.Prefix)+"_"+filepath.Base(f.Name))
+			}
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// jobName returns the generator name with its arguments.
+func jobName(job config.Generate) string {
+	return strings.Join(append([]string{job.Generator}, job.Args...), " ")
+}
+
+// stage stages files of the generator, scaffold files are merged with the user files.
+func (s *service) stage(generator string, files []generators.File) error {
+	var gen [][]byte
+	for _, f := range files {
+		if f.Kind == generators.FileGenerated && filepath.Ext(f.Name) == ".go" {
+			gen = append(gen, f.Data)
+		}
+	}
+	for _, f := range files {
+		var err error
+		filename := filepath.Join(s.outputDir(), s.cfg.Output.File(f.Name))
+		if f.Kind == generators.FileScaffold {
+			err = s.mergeStubs(generator, filename, f.Data, gen)
+		} else {
+			err = s.w.WriteGenerated(generator, filename, f.Data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateAll runs the generators of the generate section of the config concurrently,
+// their files are written only if all of them succeed.
+func (s *service) generateAll() error {
+	if len(s.cfg.Generate) == 0 {
+		return errors.New("no generators in the generate section of the config")
+	}
+	var names []string
+	for _, job := range s.cfg.Generate {
+		names = append(names, job.Generator)
+	}
+	rs, err := generators.Resolve(names...)
+	if err != nil {
+		return err
+	}
+
+	type genRun struct {
+		r       generators.Registration
+		job     config.Generate
+		files   []generators.File
+		err     error
+		elapsed time.Duration
+	}
+	var runs []*genRun
+	for _, r := range rs {
+		n := len(runs)
+		for _, job := range s.cfg.Generate {
+			if job.Generator == r.Name {
+				runs = append(runs, &genRun{r: r, job: job})
+			}
+		}
+		if len(runs) == n {
+			runs = append(runs, &genRun{r: r, job: config.Generate{Generator: r.Name}})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, run := range runs {
+		wg.Add(1)
+		go func(run *genRun) {
+			defer wg.Done()
+			start := time.Now()
+			run.files, run.err = s.generate(run.r, run.job)
+			run.elapsed = time.Since(start)
+		}(run)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, run := range runs {
+		if run.err == nil {
+			run.err = s.stage(jobName(run.job), run.files)
+		}
+		if run.err != nil {
+			failed++
+			s.log.Printf("%s: %v (%s)", jobName(run.job), run.err, run.elapsed.Round(time.Millisecond))
+			continue
+		}
+		s.log.Printf("%s: %d files (%s)", jobName(run.job), len(run.files), run.elapsed.Round(time.Millisecond))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d generators failed, no files are written", failed, len(runs))
+	}
+	return s.w.Flush()
+}
+
+// mergeStubs merges stubs into the user file filename, gen are the generated files the stubs complement.
+// Edited user code that is generated now is replaced only with the force option, stubs of removed methods
+// are pruned. The user file is removed once it has neither stubs nor user code left.
+func (s *service) mergeStubs(generator, filename string, stubs []byte, gen [][]byte) error {
+	src, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		src = nil
+	} else if err != nil {
+		return err
+	}
+
+	res, err := merge.Stubs(src, stubs, s.w.Force(), gen...)
+	if _, ok := err.(*merge.EditedError); ok {
+		return fmt.Errorf("%s: %v, move the code or use --force to replace it", filename, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	if s.w.Writes() {
+		for _, name := range res.Removed {
+			s.log.Printf("%s: removed %s, it is generated now", filename, name)
+		}
+		for _, name := range res.Pruned {
+			s.log.Printf("%s: removed stub %s, it is not needed anymore", filename, name)
+		}
+		for _, name := range res.Added {
+			s.log.Printf("%s: added stub %s", filename, name)
+		}
+	}
+	return s.w.Write(generator, filename, res.Src)
+}
+
+// outputDir returns the directory generated files are written to, the service path by default.
+func (s *service) outputDir() string {
+	if s.cfg.Output.Dir != "" {
+		return s.cfg.Output.Dir
+	}
+	return s.path
+}
+
+// templateDir returns the templates dir, .gokit/templates next to the config file by default.
+func (s *service) templateDir() string {
+	if s.cfg.Templates.Dir != "" {
+		return s.cfg.Templates.Dir
+	}
+	return filepath.Join(filepath.Dir(s.configFile), ".gokit", "templates")
+}
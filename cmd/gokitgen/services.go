@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/l-vitaly/gokitgen/pkg/check"
+	"github.com/l-vitaly/gokitgen/pkg/output"
+	"github.com/urfave/cli"
+)
+
+// serviceConfig name of the service config files the services command looks for.
+const serviceConfig = ".gokit.yaml"
+
+// servicesCommand runs the generate command of every service of the services section of the config,
+// or of every service config found under the dir argument if the section is empty, the config of the run
+// is not a service config. Services are generated by a bounded pool of workers sharing the loader
+// of imported packages.
+func servicesCommand(c *cli.Context) error {
+	configs, err := serviceConfigs(svc(c).cfg.Services, c.Args().First(), svc(c).configFile)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return fmt.Errorf("no %s files found", serviceConfig)
+	}
+
+	workers := c.Int("j")
+	if workers < 1 {
+		workers = 1
+	}
+	loader := check.NewLoader()
+	out := &syncWriter{w: os.Stdout}
+
+	type serviceRun struct {
+		config  string
+		changed []string
+		err     error
+	}
+	runs := make([]serviceRun, len(configs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				run := &runs[i]
+				run.config = configs[i]
+				w := newWriter(c, output.WriterLoader(loader), output.WriterOutput(out))
+				s, err := loadService(run.config, nil, w)
+				if err != nil {
+					run.err = err
+					continue
+				}
+				s.log = log.New(os.Stderr, filepath.Dir(run.config)+": ", log.LstdFlags)
+				run.err = s.generateAll()
+				if run.err == nil {
+					run.err = w.Err()
+				}
+				run.changed = w.Changed()
+			}
+		}()
+	}
+	for i := range configs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var changed, failed []string
+	for _, run := range runs {
+		changed = append(changed, run.changed...)
+		if run.err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", run.config, run.err))
+		}
+	}
+	verb := "changed"
+	if !writer(c).Writes() {
+		verb = "stale"
+	}
+	fmt.Fprintf(out, "%d services, %d files %s, %d failed\n", len(runs), len(changed), verb, len(failed))
+	for _, name := range changed {
+		fmt.Fprintf(out, "%s %s\n", verb, name)
+	}
+	for _, msg := range failed {
+		fmt.Fprintf(out, "failed %s\n", msg)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d services failed", len(failed), len(runs))
+	}
+	return nil
+}
+
+// serviceConfigs returns the service config files matching the patterns,
+// or found under root if there are no patterns, except the exclude file.
+func serviceConfigs(patterns []string, root, exclude string) ([]string, error) {
+	exclude, err := filepath.Abs(exclude)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{exclude: true}
+	var configs []string
+	add := func(name string) error {
+		name, err := filepath.Abs(name)
+		if err != nil {
+			return err
+		}
+		if !seen[name] {
+			seen[name] = true
+			configs = append(configs, name)
+		}
+		return nil
+	}
+
+	if len(patterns) == 0 {
+		if root == "" {
+			root = "."
+		}
+		err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				name := fi.Name()
+				if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if fi.Name() == serviceConfig {
+				return add(path)
+			}
+			return nil
+		})
+		return configs, err
+	}
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matches {
+			fi, err := os.Stat(name)
+			if err != nil {
+				return nil, err
+			}
+			if fi.IsDir() {
+				name = filepath.Join(name, serviceConfig)
+				if _, err := os.Stat(name); os.IsNotExist(err) {
+					continue
+				}
+			}
+			if err := add(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Strings(configs)
+	return configs, nil
+}
+
+// syncWriter serializes writes of the concurrently generated services.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
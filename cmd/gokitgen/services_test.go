@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestServiceConfigs(t *testing.T) {
+	root, err := ioutil.TempDir("", "services")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for _, dir := range []string{"", "a", "b/c", "d", "vendor/v", "testdata", ".git"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if dir == "d" {
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(root, dir, serviceConfig), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rootConfig := filepath.Join(root, serviceConfig)
+
+	cases := []struct {
+		name     string
+		patterns []string
+		exclude  string
+		want     []string
+		err      bool
+	}{
+		{name: "walk", exclude: rootConfig, want: []string{"a", "b/c"}},
+		{name: "walk with the root config", want: []string{"", "a", "b/c"}},
+		// patterns match the dirs the walk skips
+		{name: "dirs", patterns: []string{filepath.Join(root, "*")}, exclude: rootConfig, want: []string{".git", "a", "testdata"}},
+		{
+			name:     "dirs and files",
+			patterns: []string{filepath.Join(root, "b", "c", serviceConfig), filepath.Join(root, "*", serviceConfig), root},
+			exclude:  rootConfig,
+			want:     []string{".git", "a", "b/c", "testdata"},
+		},
+		{name: "root dir", patterns: []string{root}, want: []string{""}},
+		{name: "no match", patterns: []string{filepath.Join(root, "e*")}},
+		{name: "bad pattern", patterns: []string{"["}, err: true},
+	}
+	for _, tc := range cases {
+		got, err := serviceConfigs(tc.patterns, root, tc.exclude)
+		if (err != nil) != tc.err {
+			t.Errorf("%s: got error %v, want error %v", tc.name, err, tc.err)
+			continue
+		}
+		var want []string
+		for _, dir := range tc.want {
+			want = append(want, filepath.Join(root, dir, serviceConfig))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", tc.name, got, want)
+		}
+	}
+}
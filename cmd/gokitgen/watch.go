@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+// watchCommand polls the service package and the config and reruns the generate command
+// once they stop changing for the debounce duration. The service is only regenerated if
+// the parsed service or the config differ from the last run.
+func watchCommand(c *cli.Context) error {
+	root := c
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+	interval, debounce := c.Duration("interval"), c.Duration("debounce")
+
+	var last string
+	regenerate := func(reload bool) {
+		if reload {
+			if err := load(root); err != nil {
+				log.Print(err)
+				return
+			}
+		}
+		hash, err := watchHash(c)
+		if err != nil {
+			log.Print(err)
+			return
+		}
+		if hash == last {
+			return
+		}
+		if err := generateCommand(c); err != nil {
+			log.Print(err)
+			return
+		}
+		last = hash
+		if err := writer(c).Err(); err != nil {
+			log.Print(err)
+		}
+	}
+
+	snapshot := func() map[string]fileStamp {
+		return watchSnapshot(c)
+	}
+	regenerate(false)
+	prev := snapshot()
+	log.Printf("watching %s", svc(c).path)
+	for {
+		watchChange(snapshot, prev, interval, debounce)
+		regenerate(true)
+		// generated files must not trigger another run
+		prev = snapshot()
+	}
+}
+
+// watchChange polls the snapshot every interval until it differs from prev,
+// then every debounce until it stops changing.
+func watchChange(snapshot func() map[string]fileStamp, prev map[string]fileStamp, interval, debounce time.Duration) {
+	cur := prev
+	for reflect.DeepEqual(cur, prev) {
+		time.Sleep(interval)
+		cur = snapshot()
+	}
+	for {
+		time.Sleep(debounce)
+		next := snapshot()
+		if reflect.DeepEqual(next, cur) {
+			return
+		}
+		cur = next
+	}
+}
+
+// fileStamp modification time and size of a watched file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchSnapshot returns stamps of the Go files of the service package and of the config file.
+func watchSnapshot(c *cli.Context) map[string]fileStamp {
+	files, _ := filepath.Glob(filepath.Join(svc(c).path, "*.go"))
+	files = append(files, c.GlobalString("c"))
+	stamps := map[string]fileStamp{}
+	for _, name := range files {
+		if fi, err := os.Stat(name); err == nil {
+			stamps[name] = fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+		}
+	}
+	return stamps
+}
+
+// watchHash returns the hash of the parsed service and the config file.
+func watchHash(c *cli.Context) (string, error) {
+	h := sha256.New()
+	data, err := json.Marshal(svc(c).results)
+	if err != nil {
+		return "", err
+	}
+	h.Write(data)
+	data, err = ioutil.ReadFile(c.GlobalString("c"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
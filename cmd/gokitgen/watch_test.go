@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+	"github.com/urfave/cli"
+)
+
+// testContext returns the context of a command run for the service with the config file.
+func testContext(s *service, configFile string) *cli.Context {
+	app := cli.NewApp()
+	app.Metadata = map[string]interface{}{"service": s}
+	set := flag.NewFlagSet("gokitgen", flag.ContinueOnError)
+	set.String("c", configFile, "")
+	root := cli.NewContext(app, set, nil)
+	return cli.NewContext(app, flag.NewFlagSet("watch", flag.ContinueOnError), root)
+}
+
+func TestWatchChange(t *testing.T) {
+	a := map[string]fileStamp{"a.go": {size: 1}}
+	b := map[string]fileStamp{"a.go": {size: 2}}
+	c := map[string]fileStamp{"a.go": {size: 2}, "b.go": {size: 1}}
+	cases := []struct {
+		name      string
+		snapshots []map[string]fileStamp
+	}{
+		{"single change", []map[string]fileStamp{b, b}},
+		{"unchanged polls", []map[string]fileStamp{a, a, b, b}},
+		{"debounced changes", []map[string]fileStamp{b, c, c}},
+		{"changed back", []map[string]fileStamp{b, a, a}},
+	}
+	for _, tc := range cases {
+		calls := 0
+		snapshot := func() map[string]fileStamp {
+			calls++
+			if calls > len(tc.snapshots) {
+				return map[string]fileStamp{}
+			}
+			return tc.snapshots[calls-1]
+		}
+		watchChange(snapshot, a, 0, 0)
+		if calls != len(tc.snapshots) {
+			t.Errorf("%s: got %d snapshots, want %d", tc.name, calls, len(tc.snapshots))
+		}
+	}
+}
+
+func TestWatchSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, data string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("service.go", "package hello\n")
+	write("notes.md", "notes\n")
+	configFile := filepath.Join(dir, serviceConfig)
+	c := testContext(&service{path: dir}, configFile)
+
+	names := func(stamps map[string]fileStamp) []string {
+		var names []string
+		for name := range stamps {
+			names = append(names, filepath.Base(name))
+		}
+		sort.Strings(names)
+		return names
+	}
+	before := watchSnapshot(c)
+	if got, want := names(before), []string{"service.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("without the config: got %q, want %q", got, want)
+	}
+	write(serviceConfig, "service: hello.Service\n")
+	write("notes.md", "more notes\n")
+	after := watchSnapshot(c)
+	if got, want := names(after), []string{serviceConfig, "service.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("with the config: got %q, want %q", got, want)
+	}
+	write("service.go", "package hello\n\ntype Service interface{}\n")
+	if reflect.DeepEqual(watchSnapshot(c), after) {
+		t.Error("the changed service file is not in the snapshot")
+	}
+}
+
+func TestWatchHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, serviceConfig)
+	s := &service{results: []parser.Result{{Pkg: "hello", ServiceName: "Service"}}}
+	c := testContext(s, configFile)
+
+	hash := func() string {
+		h, err := watchHash(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	noConfig := hash()
+	if hash() != noConfig {
+		t.Error("the hash of the same service differs")
+	}
+	if err := ioutil.WriteFile(configFile, []byte("service: hello.Service\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	withConfig := hash()
+	if withConfig == noConfig {
+		t.Error("the hash does not change with the config")
+	}
+	s.results[0].Methods = []parser.Method{{Name: "Say"}}
+	if hash() == withConfig {
+		t.Error("the hash does not change with the service")
+	}
+
+	if err := os.Remove(configFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(configFile, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watchHash(c); err == nil {
+		t.Error("no error for the unreadable config")
+	}
+}
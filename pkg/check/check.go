@@ -0,0 +1,147 @@
+package check
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Loader type-checks packages, the packages they import are loaded from source once per module
+// and shared by all the checked packages of the module. It is safe for concurrent use.
+type Loader struct {
+	fset      *token.FileSet
+	mu        sync.Mutex
+	importers map[string]*sourceImporter
+}
+
+// Import imports the package from source.
+func (l *Loader) Import(path string) (*types.Package, error) {
+	return l.ImportFrom(path, "", 0)
+}
+
+// ImportFrom imports the package from source, dir is the directory of the importing package.
+func (l *Loader) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	return l.importer(dir).ImportFrom(path, dir, mode)
+}
+
+// importer returns the importer of the module containing dir, import paths are resolved in the module root.
+func (l *Loader) importer(dir string) *sourceImporter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	root := moduleRoot(dir)
+	imp, ok := l.importers[root]
+	if !ok {
+		if root != "" {
+			imp = newSourceImporter(l.fset, root)
+		} else {
+			imp = newSourceImporter(l.fset, dir)
+		}
+		l.importers[root] = imp
+	}
+	return imp
+}
+
+// moduleRoot returns the dir of the go.mod file of the module containing dir, empty if there is none.
+func moduleRoot(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !fi.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Package type-checks the package in dir along with its in-package tests.
+// Files of overlay replace the files on disk by their absolute names, nil content removes a file.
+// Packages imported by the package are not overlaid, they are cached by the loader.
+func (l *Loader) Package(dir string, overlay map[string][]byte) ([]types.Error, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := build.ImportDir(dir, 0)
+	if _, ok := err.(*build.NoGoError); err != nil && !ok {
+		return nil, err
+	}
+
+	sources := map[string][]byte{}
+	for _, name := range append(append(pkg.GoFiles, pkg.CgoFiles...), pkg.TestGoFiles...) {
+		sources[filepath.Join(dir, name)] = nil
+	}
+	for name, data := range overlay {
+		if filepath.Dir(name) != dir || filepath.Ext(name) != ".go" {
+			continue
+		}
+		if data == nil {
+			delete(sources, name)
+		} else {
+			sources[name] = data
+		}
+	}
+	var names []string
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := l.fset
+	var files []*ast.File
+	for _, name := range names {
+		var src interface{}
+		if data := sources[name]; data != nil {
+			src = data
+		}
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		// external tests are a package of their own
+		if strings.HasSuffix(f.Name.Name, "_test") {
+			continue
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	var errs []types.Error
+	conf := types.Config{
+		Importer: l,
+		Error: func(err error) {
+			if err, ok := err.(types.Error); ok {
+				errs = append(errs, err)
+			}
+		},
+	}
+	conf.Check(pkg.ImportPath, fset, files, nil)
+	return errs, nil
+}
+
+// Package type-checks the package in dir with a new loader, see Loader.Package.
+func Package(dir string, overlay map[string][]byte) ([]types.Error, error) {
+	return NewLoader().Package(dir, overlay)
+}
+
+// NewLoader creates a loader.
+func NewLoader() *Loader {
+	return &Loader{
+		fset:      token.NewFileSet(),
+		importers: map[string]*sourceImporter{},
+	}
+}
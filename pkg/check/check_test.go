@@ -0,0 +1,106 @@
+package check
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// writeModule writes the files of a module by their slash separated names into a temp dir.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["go.mod"] = "module example.com/m\n\ngo 1.18\n"
+	for name, data := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoaderPackage(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a/a.go": "package a\n\nimport \"strings\"\n\nfunc Upper(s string) string { return strings.ToUpper(s) }\n",
+		"b/b.go": "package b\n\nimport \"example.com/m/a\"\n\nvar V = a.Upper(\"b\")\n",
+		"c/c.go": "package c\n\nimport \"example.com/m/a\"\n\nvar V int = a.Upper(\"c\")\n",
+	})
+	defer os.RemoveAll(dir)
+
+	defaultDir := build.Default.Dir
+	l := NewLoader()
+	var wg sync.WaitGroup
+	errs := make([]int, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pkg := "b"
+			if i%2 == 1 {
+				pkg = "c"
+			}
+			terrs, err := l.Package(filepath.Join(dir, pkg), nil)
+			if err != nil {
+				t.Error(err)
+			}
+			errs[i] = len(terrs)
+		}(i)
+	}
+	wg.Wait()
+	if build.Default.Dir != defaultDir {
+		t.Errorf("build.Default.Dir: got %q, want %q", build.Default.Dir, defaultDir)
+	}
+	for i, n := range errs {
+		want := 0
+		if i%2 == 1 {
+			want = 1
+		}
+		if n != want {
+			t.Errorf("check %d: got %d errors, want %d", i, n, want)
+		}
+	}
+}
+
+func TestLoaderPackageOverlay(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a/a.go": "package a\n\nfunc A() int { return 1 }\n",
+	})
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "a", "gen.go")
+	errs, err := NewLoader().Package(filepath.Join(dir, "a"), map[string][]byte{
+		name: []byte("package a\n\nvar s string = A()\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Fset.Position(errs[0].Pos).Filename != name {
+		t.Errorf("got errors %v, want one error in %s", errs, name)
+	}
+}
+
+func TestLoaderImportCycle(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a/a.go": "package a\n\nimport \"example.com/m/b\"\n\nvar A = b.B\n",
+		"b/b.go": "package b\n\nimport \"example.com/m/a\"\n\nvar B = a.A\n",
+	})
+	defer os.RemoveAll(dir)
+
+	errs, err := NewLoader().Package(filepath.Join(dir, "a"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) == 0 {
+		t.Error("no error for the import cycle")
+	}
+}
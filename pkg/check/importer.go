@@ -0,0 +1,125 @@
+package check
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// sourceImporter imports packages from source, import paths are resolved with its own copy of build.Default
+// running the go command in the module root. Packages are loaded once, concurrent imports of a package
+// being loaded wait for it, imports of other packages run in parallel. It is safe for concurrent use.
+type sourceImporter struct {
+	fset  *token.FileSet
+	ctxt  build.Context
+	sizes types.Sizes
+	mu    sync.Mutex
+	pkgs  map[string]*imported
+}
+
+// imported package being loaded or loaded, done is closed once it is loaded.
+type imported struct {
+	done chan struct{}
+	pkg  *types.Package
+	err  error
+}
+
+func newSourceImporter(fset *token.FileSet, dir string) *sourceImporter {
+	ctxt := build.Default
+	ctxt.Dir = dir
+	return &sourceImporter{
+		fset:  fset,
+		ctxt:  ctxt,
+		sizes: types.SizesFor(ctxt.Compiler, ctxt.GOARCH),
+		pkgs:  map[string]*imported{},
+	}
+}
+
+func (imp *sourceImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, "", 0)
+}
+
+func (imp *sourceImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	return imp.importFrom(path, dir, nil)
+}
+
+// importFrom imports the package, stack are the dirs of the packages importing it.
+func (imp *sourceImporter) importFrom(path, dir string, stack []string) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	bp, err := imp.ctxt.Import(path, dir, 0)
+	if _, ok := err.(*build.NoGoError); err != nil && !ok {
+		return nil, err
+	}
+	for _, d := range stack {
+		if d == bp.Dir {
+			return nil, fmt.Errorf("import cycle through %s", bp.ImportPath)
+		}
+	}
+
+	imp.mu.Lock()
+	p, ok := imp.pkgs[bp.Dir]
+	if ok {
+		imp.mu.Unlock()
+		<-p.done
+		return p.pkg, p.err
+	}
+	p = &imported{done: make(chan struct{})}
+	imp.pkgs[bp.Dir] = p
+	imp.mu.Unlock()
+
+	p.pkg, p.err = imp.load(bp, append(stack[:len(stack):len(stack)], bp.Dir))
+	close(p.done)
+	return p.pkg, p.err
+}
+
+// load type-checks the package without the function bodies.
+func (imp *sourceImporter) load(bp *build.Package, stack []string) (*types.Package, error) {
+	var files []*ast.File
+	for _, name := range append(append([]string(nil), bp.GoFiles...), bp.CgoFiles...) {
+		f, err := parser.ParseFile(imp.fset, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	var hardErr error
+	conf := types.Config{
+		Importer:         stackImporter{imp: imp, stack: stack},
+		Sizes:            imp.sizes,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error: func(err error) {
+			if err, ok := err.(types.Error); hardErr == nil && ok && !err.Soft {
+				hardErr = err
+			}
+		},
+	}
+	pkg, _ := conf.Check(bp.ImportPath, imp.fset, files, nil)
+	if hardErr != nil {
+		return pkg, fmt.Errorf("type-checking package %q failed (%v)", bp.ImportPath, strings.TrimSpace(hardErr.Error()))
+	}
+	return pkg, nil
+}
+
+// stackImporter imports the packages imported by the package on top of the stack.
+type stackImporter struct {
+	imp   *sourceImporter
+	stack []string
+}
+
+func (s stackImporter) Import(path string) (*types.Package, error) {
+	return s.ImportFrom(path, "", 0)
+}
+
+func (s stackImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	return s.imp.importFrom(path, dir, s.stack)
+}
@@ -17,7 +17,198 @@ func (o *transportOptions) Unmarshal(v interface{}) error {
 	return o.unmarshal(v)
 }
 
+// Transports transport options by transport name.
+type Transports map[string]TransportOptions
+
+// UnmarshalYAML defers decoding of every transport until its options are requested.
+func (t *Transports) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw map[string]*transportOptions
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*t = Transports{}
+	for name, o := range raw {
+		(*t)[name] = o
+	}
+	return nil
+}
+
+// Unmarshal decodes options of the named transport into v,
+// v is left untouched if the transport is not configured.
+func (t Transports) Unmarshal(name string, v interface{}) error {
+	if o, ok := t[name]; ok && o != nil {
+		return o.Unmarshal(v)
+	}
+	return nil
+}
+
+// HTTPEndpoint http endpoint options.
+type HTTPEndpoint struct {
+	// Method http method, POST by default.
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+	// Path route path, parameters are declared as {name}.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Body params sent in a JSON body, all params not bound to the path or query by default.
+	Body []string `json:"body,omitempty" yaml:"body,omitempty"`
+	// Query params sent in the query string, a missing param keeps its zero value.
+	Query []string `json:"query,omitempty" yaml:"query,omitempty"`
+	// Status success status code, 204 No Content for methods without results and 200 OK for others by default.
+	Status int `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+// HTTPTransport http transport options.
+type HTTPTransport struct {
+	// Endpoints endpoint options by service method name.
+	Endpoints map[string]HTTPEndpoint `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	// Errors http status codes by service error variable name.
+	Errors map[string]int `json:"errors,omitempty" yaml:"errors,omitempty"`
+	// Router router of the handler: mux (gorilla/mux), chi (go-chi/chi), httprouter (julienschmidt/httprouter)
+	// or servemux (net/http.ServeMux of Go 1.22), mux by default.
+	Router string `json:"router,omitempty" yaml:"router,omitempty"`
+}
+
+// Endpoint endpoint options.
+type Endpoint struct {
+	// Errors error strategy of the endpoints: response keeps business errors in the responses implementing
+	// endpoint.Failer, endpoint returns them as endpoint errors, response by default.
+	Errors string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Breaker circuit breaker of a client endpoint.
+type Breaker struct {
+	// Failures consecutive failures opening the breaker, 5 by default.
+	Failures int `json:"failures,omitempty" yaml:"failures,omitempty"`
+	// Timeout period of the open breaker rejecting calls before it lets MaxRequests calls through, 60s by default.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// MaxRequests calls let through the half-open breaker, 1 by default.
+	MaxRequests int `json:"maxRequests,omitempty" yaml:"maxRequests,omitempty"`
+}
+
+// RateLimit token bucket rate limit of a client endpoint, calls over the limit fail.
+type RateLimit struct {
+	// Rate tokens added to the bucket per second.
+	Rate float64 `json:"rate" yaml:"rate"`
+	// Burst bucket size, the rate rounded up by default.
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty"`
+}
+
+// Retry retries of the failed client endpoint calls.
+type Retry struct {
+	// Attempts calls of the endpoint at most, 3 by default.
+	Attempts int `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	// Backoff delay before the second attempt, it doubles before every next one, 100ms by default.
+	Backoff string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	// Timeout time of all attempts, 10s by default.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// EndpointResilience resilience middlewares of a client endpoint, durations are Go durations, e.g. 2s.
+type EndpointResilience struct {
+	// Timeout of an endpoint call.
+	Timeout   string     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Breaker   *Breaker   `json:"breaker,omitempty" yaml:"breaker,omitempty"`
+	RateLimit *RateLimit `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	Retry     *Retry     `json:"retry,omitempty" yaml:"retry,omitempty"`
+}
+
+// Resilience resilience middlewares of the client endpoints.
+type Resilience struct {
+	// Defaults middlewares of all endpoints.
+	Defaults EndpointResilience `json:"defaults" yaml:"defaults"`
+	// Methods middlewares by service method name, they replace the defaults.
+	Methods map[string]EndpointResilience `json:"methods,omitempty" yaml:"methods,omitempty"`
+}
+
+// JSON JSON encoding of the endpoint request and response structs, transports encode the structs with it.
+type JSON struct {
+	// Naming wire names of the fields: go (the struct field names), camel (camelCase) or snake (snake_case),
+	// go by default.
+	Naming string `json:"naming,omitempty" yaml:"naming,omitempty"`
+	// OmitEmpty omits fields with empty values.
+	OmitEmpty bool `json:"omitEmpty,omitempty" yaml:"omitEmpty,omitempty"`
+	// Fields tags of the params and results by service method name and param or result name, e.g.
+	// Say: {name: full_name}. A tag is the wire name with optional options replacing OmitEmpty,
+	// e.g. "full_name,omitempty", ",omitempty" keeps the wire name of the naming, "-" omits the field and "-," names it "-".
+	Fields map[string]map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// TemplateGenerator generator executing a template with the service data model.
+type TemplateGenerator struct {
+	// Template template file name looked up in the templates dir.
+	Template string `yaml:"template"`
+	// Output generated file name relative to the output dir.
+	Output string `yaml:"output"`
+	// Options values available to the template as .Options.
+	Options map[string]interface{} `yaml:"options"`
+}
+
+// Templates template options.
+type Templates struct {
+	// Dir directory with templates overriding the built-in ones, .gokit/templates by default.
+	Dir string `yaml:"dir"`
+	// Generators template generators by name.
+	Generators map[string]TemplateGenerator `yaml:"generators"`
+}
+
+// Plugin external generator options.
+type Plugin struct {
+	// Path plugin binary, gokitgen-<name> looked up in PATH by default.
+	Path string `yaml:"path"`
+	// Options values passed to the plugin.
+	Options map[string]interface{} `yaml:"options"`
+}
+
+// Output output options.
+type Output struct {
+	// Dir directory generated files are written to, the service path by default.
+	Dir string `yaml:"dir"`
+	// Files file names by the names generators give them, e.g. http_gen.go: transport_gen.go.
+	Files map[string]string `yaml:"files"`
+}
+
+// File returns the configured name of the generated file name.
+func (o Output) File(name string) string {
+	if n := o.Files[name]; n != "" {
+		return n
+	}
+	return name
+}
+
+// Generate generator run by the generate command.
+type Generate struct {
+	// Generator registered generator name, e.g. http.
+	Generator string `json:"generator" yaml:"generator"`
+	// Args generator arguments, e.g. the template generator name.
+	Args []string `json:"args,omitempty" yaml:"args,omitempty"`
+	// Flags generator flags, they override the generators section.
+	Flags map[string]bool `json:"flags,omitempty" yaml:"flags,omitempty"`
+}
+
 type Config struct {
-	Service string
-	Path    string
+	Service string `yaml:"service"`
+	// Discover generates every interface of the package marked by the //gokit:service directive
+	// if no service is set.
+	Discover   bool       `yaml:"discover"`
+	Path       string     `yaml:"path"`
+	Transports Transports `yaml:"transports"`
+	// Endpoint endpoint options shared by the generators.
+	Endpoint Endpoint `yaml:"endpoint"`
+	// Resilience resilience middlewares of the client endpoints.
+	Resilience Resilience `yaml:"resilience"`
+	// JSON JSON encoding of the request and response structs. Errors are never encoded,
+	// transports send them separately.
+	JSON JSON `yaml:"json"`
+	// Validate validation rules of the params by service method and param name, e.g.
+	// Say: {name: required maxlen=64}, they replace the rules of the //gokit:validate method annotations.
+	Validate  map[string]map[string]string `yaml:"validate"`
+	Templates Templates                    `yaml:"templates"`
+	Output    Output                       `yaml:"output"`
+	Plugins   map[string]Plugin            `yaml:"plugins"`
+	// Generators generator flags by generator name, e.g. http: {zipkin: true}.
+	Generators map[string]map[string]bool `yaml:"generators"`
+	// Generate generators run by the generate command, generators they require are run as well.
+	Generate []Generate `yaml:"generate"`
+	// Services globs of service config files or of dirs containing .gokit.yaml, relative to the config file,
+	// the services command generates them.
+	Services []string `yaml:"services"`
 }
@@ -0,0 +1,235 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+
+	"github.com/l-vitaly/gokitgen/pkg/naming"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+	"github.com/l-vitaly/gokitgen/pkg/utils"
+)
+
+// EndpointTransportDataField field of an endpoint request or response struct.
+type EndpointTransportDataField struct {
+	// Field service method param or result.
+	Field parser.Field
+	// Name exported struct field name.
+	Name string
+	// Tag struct tag of the field, e.g. `json:"name,omitempty"`, empty if the field has none.
+	Tag string
+}
+
+// EndpointTransportData endpoint request or response struct.
+type EndpointTransportData struct {
+	Name   string
+	Fields []EndpointTransportDataField
+}
+
+// Endpoint go-kit endpoint of a service method.
+type Endpoint struct {
+	// Name endpoint field name in the endpoints set, e.g. SayEndpoint.
+	Name string
+	// ServiceName name of the service interface.
+	ServiceName string
+	// Prefix prefix of the generated identifiers, see Data.Prefix.
+	Prefix string
+	// TypeParams type parameters of a generic service, declarations of the
+	// generated types and functions are parameterized with them.
+	TypeParams []parser.Field
+	Method     parser.Method
+	Request    EndpointTransportData
+	Response   EndpointTransportData
+	// Validation validations of the request fields, see ValidateDirective.
+	Validation []FieldValidation
+	// Resilience resilience middlewares of the client endpoint, nil if it has none.
+	Resilience *Resilience
+	// EndpointErrors business errors are returned as endpoint errors instead of in the response,
+	// see ErrorsInEndpoint.
+	EndpointErrors bool
+}
+
+// Ident returns the generated identifier name with the service prefix, see Data.Ident.
+func (e Endpoint) Ident(name string) string {
+	return ident(e.Prefix, name)
+}
+
+// Func returns the name of a generated function of the endpoint, format gets
+// the method name, e.g. "decodeHTTP%sRequest".
+func (e Endpoint) Func(format string) string {
+	return e.Ident(fmt.Sprintf(format, e.Method.Name))
+}
+
+// Params returns request fields without the context.
+func (e Endpoint) Params() []EndpointTransportDataField {
+	var params []EndpointTransportDataField
+	for _, f := range e.Request.Fields {
+		if !f.Field.IsContext() {
+			params = append(params, f)
+		}
+	}
+	return params
+}
+
+// Context returns the name of the context param or an empty string.
+func (e Endpoint) Context() string {
+	for _, p := range e.Method.Params {
+		if p.IsContext() {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// ErrorField returns the response field of the first error result or nil.
+func (e Endpoint) ErrorField() *EndpointTransportDataField {
+	for _, f := range e.Response.Fields {
+		if f.Field.IsError() {
+			f := f
+			return &f
+		}
+	}
+	return nil
+}
+
+// Results returns response fields without the first error result.
+func (e Endpoint) Results() []EndpointTransportDataField {
+	var results []EndpointTransportDataField
+	skipped := false
+	for _, f := range e.Response.Fields {
+		if f.Field.IsError() && !skipped {
+			skipped = true
+			continue
+		}
+		results = append(results, f)
+	}
+	return results
+}
+
+// Endpoints endpoints of a service.
+type Endpoints struct {
+	Pkg         string
+	ServiceName string
+	List        []Endpoint
+}
+
+func newEndpoints(result parser.Result) Endpoints {
+	endpoints := Endpoints{
+		Pkg:         result.Pkg,
+		ServiceName: result.ServiceName,
+	}
+	for _, m := range result.Methods {
+		lcName := naming.Unexported(m.Name)
+
+		var respFields []EndpointTransportDataField
+		var reqFields []EndpointTransportDataField
+
+		for i, name := range naming.FieldNames(m.Params) {
+			reqFields = append(reqFields, EndpointTransportDataField{
+				Name:  name,
+				Field: m.Params[i],
+			})
+		}
+
+		for i, name := range naming.FieldNames(m.Results) {
+			respFields = append(respFields, EndpointTransportDataField{
+				Name:  name,
+				Field: m.Results[i],
+			})
+		}
+
+		endpoints.List = append(endpoints.List, Endpoint{
+			Name:        m.Name + "Endpoint",
+			ServiceName: result.ServiceName,
+			Prefix:      result.Prefix,
+			TypeParams:  result.TypeParams,
+			Method:      m,
+			Request: EndpointTransportData{
+				Name:   ident(result.Prefix, lcName+"Request"),
+				Fields: reqFields,
+			},
+			Response: EndpointTransportData{
+				Name:   ident(result.Prefix, lcName+"Response"),
+				Fields: respFields,
+			},
+		})
+	}
+	return endpoints
+}
+
+// HTTPData http transport model.
+type HTTPData struct {
+	// Routes routes of all endpoints in the service method order.
+	Routes []HTTPRoute
+	// Errors service errors with their status codes, ErrBadRequest is always first.
+	Errors []HTTPError
+	// Router router of the handler, see HTTPRouterMux.
+	Router string
+}
+
+// Data is the model every template is executed with, it is derived from parser.Result.
+//
+// Templates may also use the functions lcFirst, ucFirst, kebab, join, typeArgs
+// (the type argument list of type parameters, e.g. [K, V]),
+// dict (builds a map of key and value pairs), status (http status constant
+// name), sample (a literal of a basic type field) and the http param helpers
+// parseParam, convertParam and formatParam.
+//
+// Go templates declare packages they refer to with import, e.g.
+// {{import "github.com/go-kit/kit/transport/http" "kithttp"}}, place the imports
+// block with {{imports}} and write field types with typeOf and paramType, which
+// name packages of the service types. Only packages the code refers to are imported.
+type Data struct {
+	// Pkg package name of the generated file.
+	Pkg string
+	// ServiceName name of the service interface.
+	ServiceName string
+	// Prefix prefix of the generated identifiers of a service sharing its package with other services,
+	// templates name package level declarations with Ident.
+	Prefix string
+	// Result parsed service.
+	Result parser.Result
+	// Endpoints endpoints of the service methods in declaration order.
+	Endpoints []Endpoint
+	// HTTP http transport model.
+	HTTP HTTPData
+	// Options generator options, e.g. "zipkin", "client" or options of a
+	// template generator declared in the config.
+	Options map[string]interface{}
+}
+
+// Ident returns the generated identifier name with the service prefix,
+// the identifier stays exported or unexported, e.g. "set" -> "greeterSet".
+func (d Data) Ident(name string) string {
+	return ident(d.Prefix, name)
+}
+
+func ident(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if ast.IsExported(name) {
+		return naming.Exported(prefix) + name
+	}
+	// only the first word changes its case, e.g. "httpErrors" -> "HTTPErrors"
+	words := utils.Words(name)
+	if len(words) == 0 {
+		return naming.Unexported(prefix)
+	}
+	return naming.Unexported(prefix) + naming.Exported(words[0]) + name[len(words[0]):]
+}
+
+// newData builds the template model, params and results clashing with the generated code are renamed.
+func newData(result parser.Result, options map[string]interface{}) Data {
+	result = naming.Resolve(result)
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	return Data{
+		Pkg:         result.Pkg,
+		ServiceName: result.ServiceName,
+		Prefix:      result.Prefix,
+		Result:      result,
+		Endpoints:   newEndpoints(result).List,
+		Options:     options,
+	}
+}
@@ -1,274 +1,122 @@
 package generators
 
 import (
-	"bytes"
 	"fmt"
-	"go/format"
 
+	"github.com/l-vitaly/gokitgen/pkg/config"
 	"github.com/l-vitaly/gokitgen/pkg/parser"
-	"github.com/l-vitaly/gokitgen/pkg/utils"
 )
 
-const endpointStructName = "set"
-
-type EndpointTransportDataField struct {
-	Field parser.Field
-	Name  string
-}
-
-type EndpointTransportData struct {
-	Name   string
-	Feilds []EndpointTransportDataField
-}
-
-type Endpoint struct {
-	Name     string
-	Method   parser.Method
-	Request  EndpointTransportData
-	Response EndpointTransportData
-}
-
-type Endpoints struct {
-	Pkg         string
-	ServiceName string
-	List        []Endpoint
-}
-
-type EndpointGenerator struct {
-	buf bytes.Buffer
-}
-
-func (g *EndpointGenerator) printf(format string, args ...interface{}) {
-	fmt.Fprintf(&g.buf, format, args...)
-}
+// Error strategies of the endpoints.
+const (
+	// ErrorsInResponse business errors are kept in the responses, they implement endpoint.Failer.
+	ErrorsInResponse = "response"
+	// ErrorsInEndpoint business errors are returned as endpoint errors.
+	ErrorsInEndpoint = "endpoint"
+)
 
-func (g *EndpointGenerator) format() ([]byte, error) {
-	src, err := format.Source(g.buf.Bytes())
-	if err != nil {
-		fmt.Println(g.buf.String())
-		return nil, err
+// errorStrategy sets the error strategy of the endpoints.
+func errorStrategy(endpoints []Endpoint, strategy string) error {
+	switch strategy {
+	case "", ErrorsInResponse, ErrorsInEndpoint:
+	default:
+		return fmt.Errorf("endpoint: unknown error strategy %q, use %s or %s", strategy, ErrorsInResponse, ErrorsInEndpoint)
 	}
-	return src, nil
-}
-
-func (g *EndpointGenerator) declareEndpoint(endpoints Endpoints) {
-	g.printf("// Set collects all of the endpoints that compose an %s service.\n", endpoints.ServiceName)
-	g.printf("type %s struct {\n", endpointStructName)
-	for _, e := range endpoints.List {
-		g.printf("\t%s endpoint.Endpoint\n", e.Name)
+	for i := range endpoints {
+		endpoints[i].EndpointErrors = strategy == ErrorsInEndpoint
 	}
-	g.printf("}\n\n")
+	return nil
 }
 
-func (g *EndpointGenerator) declareRequestResponse(endpoints Endpoints) {
-	for _, e := range endpoints.List {
-		if len(e.Request.Feilds) > 0 {
-			g.printf("type %s struct {\n", e.Request.Name)
-			for _, f := range e.Request.Feilds {
-				if f.Field.TypeWithPkg() == "context.Context" {
-					continue
-				}
-				g.printf("\t%s %s\n", f.Name, f.Field.Type)
-			}
-			g.printf("}\n\n")
-		}
-
-		if len(e.Response.Feilds) > 0 {
-			g.printf("type %s struct {\n", e.Response.Name)
-
-			errField := ""
-			for _, f := range e.Response.Feilds {
-				g.printf("\t%s %s\n", f.Name, f.Field.Type)
-
-				if errField == "" && f.Field.Type == "error" {
-					errField = f.Name
-				}
+// EndpointGeneratorOption endpoint generator option.
+type EndpointGeneratorOption func(g *EndpointGenerator)
 
-			}
-			g.printf("}\n\n")
-
-			if errField != "" {
-				g.printf("func (r %s) Error() error { return r.%s }\n\n", e.Response.Name, errField)
-			}
-		}
+// EndpointGeneratorTemplateDir directory with templates overriding the built-in ones.
+func EndpointGeneratorTemplateDir(dir string) EndpointGeneratorOption {
+	return func(g *EndpointGenerator) {
+		g.templateDir = dir
 	}
 }
 
-func (g *EndpointGenerator) declareMakeFuncs(endpoints Endpoints) {
-	for _, e := range endpoints.List {
-		g.printf("func make%s(s %s) endpoint.Endpoint {\n", e.Name, endpoints.ServiceName)
-		g.printf("\treturn func(ctx context.Context, request interface{}) (interface{}, error) {\n")
-
-		if len(e.Request.Feilds) > 0 {
-			g.printf("\treq := request.(%s)\n", e.Request.Name)
-		}
-
-		if len(e.Response.Feilds) > 0 {
-			g.printf("\t")
-			for i, f := range e.Response.Feilds {
-				if i > 0 {
-					g.printf(",")
-				}
-				g.printf(f.Field.Name)
-			}
-
-			g.printf(" := ")
-		}
-
-		g.printf("s.%s", e.Method.Name)
-
-		g.printf("(")
-
-		for i, f := range e.Request.Feilds {
-			if i > 0 {
-				g.printf(",")
-			}
-
-			if f.Field.TypeWithPkg() == "context.Context" {
-				g.printf("ctx")
-			} else {
-				g.printf("req.%s", f.Name)
-			}
-
-		}
-
-		g.printf(")\n")
-
-		if len(e.Response.Feilds) > 0 {
-			g.printf("\treturn %s{\n", e.Response.Name)
-			for _, f := range e.Response.Feilds {
-				g.printf("\t%s: %s,\n", f.Name, f.Field.Name)
-			}
-			g.printf("}, nil")
-		} else {
-			g.printf("\treturn nil, nil")
-		}
-
-		g.printf("\t}\n")
-
-		g.printf("}\n\n")
+// EndpointGeneratorJSON JSON encoding of the request and response structs.
+func EndpointGeneratorJSON(cfg config.JSON) EndpointGeneratorOption {
+	return func(g *EndpointGenerator) {
+		g.json = cfg
 	}
 }
 
-func (g *EndpointGenerator) declareImports(endpoints Endpoints, mapImport parser.MapImport) {
-	g.printf("import(\n")
-
-	g.printf("\t\"github.com/go-kit/kit/endpoint\"\n")
-
-	for _, e := range endpoints.List {
-		for _, f := range e.Request.Feilds {
-			if f.Field.Pkg != "" && f.Field.Pkg != endpoints.Pkg {
-				if importName, ok := mapImport.Get(f.Field.Pkg); ok {
-					g.printf("\t\"%s\"\n", importName)
-				}
-			}
-		}
-		for _, f := range e.Response.Feilds {
-			if f.Field.Pkg != "" && f.Field.Pkg != endpoints.Pkg {
-				if importName, ok := mapImport.Get(f.Field.Pkg); ok {
-					g.printf("\t\"%s\"\n", importName)
-				}
-			}
-		}
+// EndpointGeneratorValidate validation rules of the params by service method and param name.
+func EndpointGeneratorValidate(rules map[string]map[string]string) EndpointGeneratorOption {
+	return func(g *EndpointGenerator) {
+		g.validate = rules
 	}
-
-	g.printf(")\n\n")
 }
 
-func (g *EndpointGenerator) declareMethods(result parser.Result) {
-	for _, m := range result.Methods {
-		g.printf("// %s implemented interface.\n", m.Name)
-		g.printf("func (s %s) %s", endpointStructName, m.Name)
-		g.printf("(")
-
-		for i, p := range m.Params {
-			if i > 0 {
-				g.printf(",")
-			}
-
-			g.printf("%s %s", p.Name, p.TypeWithPkg())
-		}
-		g.printf(")")
-
-		if len(m.Results) > 1 {
-			g.printf("(")
-		}
-
-		for i, r := range m.Results {
-			if i > 0 {
-				g.printf(",")
-			}
-			g.printf("%s", r.Type)
-
-		}
-
-		if len(m.Results) > 1 {
-			g.printf(")")
-		}
-
-		g.printf("{\n")
-
-		g.printf("panic(\"endpoint not implemented %s\")", m.Name)
-
-		g.printf("}\n\n")
+// EndpointGeneratorErrors error strategy, ErrorsInResponse by default.
+func EndpointGeneratorErrors(strategy string) EndpointGeneratorOption {
+	return func(g *EndpointGenerator) {
+		g.errors = strategy
 	}
 }
 
-func (g *EndpointGenerator) declareFailer() {
-	g.printf("\ntype errorer interface {\n\tError() error\n}\n\n")
+// EndpointGeneratorResilience resilience middlewares of the client endpoints.
+func EndpointGeneratorResilience(cfg config.Resilience) EndpointGeneratorOption {
+	return func(g *EndpointGenerator) {
+		g.resilience = cfg
+	}
 }
 
-func (g *EndpointGenerator) Generate(result parser.Result) ([]byte, error) {
-	g.printf("package %s\n\n", result.Pkg)
+type EndpointGenerator struct {
+	templateDir string
+	json        config.JSON
+	validate    map[string]map[string]string
+	errors      string
+	resilience  config.Resilience
+}
 
-	endpoints := Endpoints{
-		Pkg:         result.Pkg,
-		ServiceName: result.ServiceName,
+func (g *EndpointGenerator) Generate(result parser.Result) ([]File, error) {
+	data := newData(result, nil)
+	if err := jsonTags(data.Endpoints, g.json); err != nil {
+		return nil, err
 	}
-	for _, m := range result.Methods {
-		lcName := utils.LcFirst(m.Name)
-
-		var respFields []EndpointTransportDataField
-		var reqFields []EndpointTransportDataField
-
-		for _, f := range m.Params {
-			reqFields = append(reqFields, EndpointTransportDataField{
-				Name:  utils.UcFirst(f.Name),
-				Field: f,
-			})
-		}
-
-		for _, f := range m.Results {
-			respFields = append(respFields, EndpointTransportDataField{
-				Name:  utils.UcFirst(f.Name),
-				Field: f,
-			})
-		}
-
-		endpoints.List = append(endpoints.List, Endpoint{
-			Name:   m.Name + "Endpoint",
-			Method: m,
-			Request: EndpointTransportData{
-				Name:   lcName + "Request",
-				Feilds: reqFields,
-			},
-			Response: EndpointTransportData{
-				Name:   lcName + "Response",
-				Feilds: respFields,
-			},
-		})
+	if err := validations(data.Endpoints, g.validate); err != nil {
+		return nil, err
 	}
+	if err := errorStrategy(data.Endpoints, g.errors); err != nil {
+		return nil, err
+	}
+	if err := resilience(data.Endpoints, g.resilience); err != nil {
+		return nil, err
+	}
+	src, err := renderTemplate("endpoints.go.tmpl", g.templateDir, data)
+	if err != nil {
+		return nil, err
+	}
+	return []File{{Name: "endpoints.go", Data: src}}, nil
+}
 
-	g.declareImports(endpoints, result.MapImport)
-	g.declareFailer()
-	g.declareEndpoint(endpoints)
-	g.declareMethods(result)
-	g.declareMakeFuncs(endpoints)
-	g.declareRequestResponse(endpoints)
-
-	return g.format()
+func init() {
+	Register(Registration{
+		Name:    "endpoint",
+		Command: []string{"endpoint"},
+		Aliases: []string{"e"},
+		Usage:   "generates go-kit endpoints of the service",
+		New: func(o Options) (Generator, error) {
+			return NewEndpoint(
+				EndpointGeneratorTemplateDir(o.TemplateDir),
+				EndpointGeneratorJSON(o.Config.JSON),
+				EndpointGeneratorValidate(o.Config.Validate),
+				EndpointGeneratorErrors(o.Config.Endpoint.Errors),
+				EndpointGeneratorResilience(o.Config.Resilience),
+			), nil
+		},
+	})
 }
 
-func NewEndpoint() *EndpointGenerator {
-	return &EndpointGenerator{}
+func NewEndpoint(options ...EndpointGeneratorOption) *EndpointGenerator {
+	g := &EndpointGenerator{}
+	for _, o := range options {
+		o(g)
+	}
+	return g
 }
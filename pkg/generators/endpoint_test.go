@@ -0,0 +1,164 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+)
+
+// genericResult returns the test service with type parameters
+//
+//	type Service[K comparable, V model.Item] interface {
+//		Say(ctx context.Context, key K) (items []othermodel.List[V], err error)
+//		Get(ctx context.Context, id int, verbose bool) (err error)
+//	}
+//
+// where othermodel is another package named model.
+func genericResult() parser.Result {
+	result := testResult()
+	result.TypeParams = []parser.Field{
+		{Name: "K", Type: "comparable"},
+		{Name: "V", Type: "model.Item", Imports: map[string]string{"model": "example.com/model"}},
+	}
+	result.Methods[0].Params[1] = parser.Field{Name: "key", Type: "K"}
+	result.Methods[0].Results[0] = parser.Field{Name: "items", Type: "[]model.List[V]", Imports: map[string]string{"model": "example.com/other/model"}}
+	return result
+}
+
+func TestGenericService(t *testing.T) {
+	cases := []struct {
+		generator string
+		file      string
+		want      []string
+	}{
+		{
+			generator: "endpoint",
+			file:      "endpoints.go",
+			want: []string{
+				"\"example.com/model\"",
+				"othermodel \"example.com/other/model\"",
+				"type set[K comparable, V model.Item] struct {",
+				"func (s set[K, V]) Say(ctx context.Context, key K) (items []othermodel.List[V], err error) {",
+				"s.SayEndpoint(ctx, sayRequest[K, V]{Key: key})",
+				"func makeSayEndpoint[K comparable, V model.Item](s Service[K, V]) endpoint.Endpoint {",
+				"type sayResponse[K comparable, V model.Item] struct {",
+				"func (r getResponse[K, V]) Failed() error",
+			},
+		},
+		{
+			generator: "logging",
+			file:      "logging.go",
+			want: []string{
+				"type loggingService[K comparable, V model.Item] struct {",
+				"func (s *loggingService[K, V]) Say(ctx context.Context, key K) (items []othermodel.List[V], err error) {",
+				"func NewLoggingService[K comparable, V model.Item](next Service[K, V], logger log.Logger) Service[K, V] {",
+			},
+		},
+	}
+	for _, tc := range cases {
+		r, _ := Lookup(tc.generator)
+		g, err := r.New(Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		files, err := g.Generate(genericResult())
+		if err != nil {
+			t.Errorf("%s: %v", tc.generator, err)
+			continue
+		}
+		var src string
+		for _, f := range files {
+			if f.Name == tc.file {
+				src = string(f.Data)
+			}
+		}
+		for _, s := range tc.want {
+			if !strings.Contains(src, s) {
+				t.Errorf("%s: %q not found in\n%s", tc.generator, s, src)
+			}
+		}
+	}
+}
+
+func TestErrorStrategy(t *testing.T) {
+	cases := []struct {
+		strategy       string
+		endpointErrors bool
+		err            bool
+	}{
+		{"", false, false},
+		{ErrorsInResponse, false, false},
+		{ErrorsInEndpoint, true, false},
+		{"panic", false, true},
+	}
+	for _, tc := range cases {
+		endpoints := newData(testResult(), nil).Endpoints
+		err := errorStrategy(endpoints, tc.strategy)
+		if (err != nil) != tc.err {
+			t.Errorf("errorStrategy(%q): got error %v, want error %v", tc.strategy, err, tc.err)
+			continue
+		}
+		for _, e := range endpoints {
+			if e.EndpointErrors != tc.endpointErrors {
+				t.Errorf("errorStrategy(%q): %s endpoint errors %v, want %v", tc.strategy, e.Name, e.EndpointErrors, tc.endpointErrors)
+			}
+		}
+	}
+}
+
+func TestEndpointErrors(t *testing.T) {
+	returned := "s.Say(ctx, req.Name)\n\t\tif err != nil {\n\t\t\treturn nil, err\n\t\t}"
+	wrapped := "sayEndpoint = endpointErrors(sayEndpoint)"
+	cases := []struct {
+		strategy string
+		want     []string
+		wantNot  []string
+		err      string
+	}{
+		{
+			strategy: ErrorsInResponse,
+			want:     []string{"func (r sayResponse) Failed() error { return r.Err }"},
+			wantNot:  []string{returned, wrapped},
+		},
+		{
+			strategy: ErrorsInEndpoint,
+			want:     []string{returned, wrapped, "if err := Failure(response, err); err != nil {"},
+		},
+		{strategy: "panic", err: `endpoint: unknown error strategy "panic"`},
+	}
+	for _, tc := range cases {
+		o := Options{
+			Flags:  map[string]bool{"c": true},
+			HTTP:   testHTTPConfig(""),
+			Config: config.Config{Endpoint: config.Endpoint{Errors: tc.strategy}},
+		}
+		var src string
+		for _, name := range []string{"endpoint", "http"} {
+			files, err := generate(name, o)
+			if tc.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+					t.Errorf("%s with %q: got error %v, want %q", name, tc.strategy, err, tc.err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s with %q: %v", name, tc.strategy, err)
+			}
+			for _, data := range files {
+				src += data
+			}
+		}
+		for _, s := range tc.want {
+			if !strings.Contains(src, s) {
+				t.Errorf("%q: %q not found in\n%s", tc.strategy, s, src)
+			}
+		}
+		for _, s := range tc.wantNot {
+			if strings.Contains(src, s) {
+				t.Errorf("%q: %q found in\n%s", tc.strategy, s, src)
+			}
+		}
+	}
+}
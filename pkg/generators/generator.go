@@ -2,6 +2,25 @@ package generators
 
 import "github.com/l-vitaly/gokitgen/pkg/parser"
 
+// FileKind kind of a generated file.
+type FileKind int
+
+const (
+	// FileGenerated file owned by the generator, it is overwritten on every run.
+	FileGenerated FileKind = iota
+	// FileScaffold file owned by the user, missing stubs are merged into it
+	// and declarations of the generated files of the same run are removed from it.
+	FileScaffold
+)
+
+// File generated file.
+type File struct {
+	// Name file name relative to the output dir.
+	Name string
+	Data []byte
+	Kind FileKind
+}
+
 type Generator interface {
-	Generate(result parser.Result) ([]byte, error)
+	Generate(result parser.Result) ([]File, error)
 }
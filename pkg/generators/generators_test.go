@@ -0,0 +1,57 @@
+package generators
+
+import (
+	"fmt"
+
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+)
+
+var contextImports = map[string]string{"context": "context"}
+
+// testResult returns the parsed service
+//
+//	type Service interface {
+//		Say(ctx context.Context, name string) (message string, err error)
+//		Get(ctx context.Context, id int, verbose bool) (err error)
+//	}
+func testResult() parser.Result {
+	ctx := parser.Field{Name: "ctx", Type: "context.Context", Imports: contextImports}
+	return parser.Result{
+		Pkg:         "hello",
+		ServiceName: "Service",
+		Name:        "Service",
+		Methods: []parser.Method{
+			{
+				Name:    "Say",
+				Params:  []parser.Field{ctx, {Name: "name", Type: "string"}},
+				Results: []parser.Field{{Name: "message", Type: "string"}, {Name: "err", Type: "error"}},
+			},
+			{
+				Name:    "Get",
+				Params:  []parser.Field{ctx, {Name: "id", Type: "int"}, {Name: "verbose", Type: "bool"}},
+				Results: []parser.Field{{Name: "err", Type: "error"}},
+			},
+		},
+	}
+}
+
+// generate creates the registered generator with the options and runs it for the test service.
+func generate(name string, o Options) (map[string]string, error) {
+	r, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("generator %s is not registered", name)
+	}
+	g, err := r.New(o)
+	if err != nil {
+		return nil, err
+	}
+	files, err := g.Generate(testResult())
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]string{}
+	for _, f := range files {
+		m[f.Name] = string(f.Data)
+	}
+	return m, nil
+}
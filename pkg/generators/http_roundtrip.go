@@ -0,0 +1,218 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+)
+
+// HTTPTestGeneratorOption http test generator option.
+type HTTPTestGeneratorOption func(g *httpTestGenerator)
+
+// HTTPTestGeneratorZipkin handler and client are created with a zipkin tracer.
+func HTTPTestGeneratorZipkin(zipkin bool) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.zipkin = zipkin
+	}
+}
+
+// HTTPTestGeneratorLogger handler and client are created with a logger.
+func HTTPTestGeneratorLogger(logger bool) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.logger = logger
+	}
+}
+
+// HTTPTestGeneratorJWT handler is generated with the JWT option.
+func HTTPTestGeneratorJWT(jwt bool) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.jwt = jwt
+	}
+}
+
+// HTTPTestGeneratorGenericRequest requests of unconfigured routes are encoded with the generic JSON encoder.
+func HTTPTestGeneratorGenericRequest(genericRequest bool) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.genericRequest = genericRequest
+	}
+}
+
+// HTTPTestGeneratorGenericResponse responses of unconfigured routes are encoded with the generic JSON encoder.
+func HTTPTestGeneratorGenericResponse(genericResponse bool) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.genericResponse = genericResponse
+	}
+}
+
+// HTTPTestGeneratorConfig routes and error codes from the http transport config.
+func HTTPTestGeneratorConfig(cfg config.HTTPTransport) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.cfg = cfg
+	}
+}
+
+// sampleValues literals of basic types used as test params.
+var sampleValues = map[string]string{
+	"string":  `"%s"`,
+	"bool":    "true",
+	"int":     "%d",
+	"int8":    "%d",
+	"int16":   "%d",
+	"int32":   "%d",
+	"int64":   "%d",
+	"uint":    "%d",
+	"uint8":   "%d",
+	"uint16":  "%d",
+	"uint32":  "%d",
+	"uint64":  "%d",
+	"float32": "%d.5",
+	"float64": "%d.5",
+}
+
+// HTTPTestGeneratorErrors error strategy of the endpoints, see HTTPGeneratorErrors.
+func HTTPTestGeneratorErrors(strategy string) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.errors = strategy
+	}
+}
+
+// HTTPTestGeneratorJSON JSON encoding of the request structs, see HTTPGeneratorJSON.
+func HTTPTestGeneratorJSON(cfg config.JSON) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.json = cfg
+	}
+}
+
+// HTTPTestGeneratorResilience resilience middlewares of the client endpoints, see HTTPGeneratorResilience.
+func HTTPTestGeneratorResilience(cfg config.Resilience) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.resilience = cfg
+	}
+}
+
+// HTTPTestGeneratorValidate validation rules of the params, test params pass them.
+func HTTPTestGeneratorValidate(rules map[string]map[string]string) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.validate = rules
+	}
+}
+
+// HTTPTestGeneratorTemplateDir directory with templates overriding the built-in ones.
+func HTTPTestGeneratorTemplateDir(dir string) HTTPTestGeneratorOption {
+	return func(g *httpTestGenerator) {
+		g.templateDir = dir
+	}
+}
+
+type httpTestGenerator struct {
+	templateDir     string
+	cfg             config.HTTPTransport
+	zipkin          bool
+	logger          bool
+	jwt             bool
+	genericRequest  bool
+	genericResponse bool
+	validate        map[string]map[string]string
+	errors          string
+	json            config.JSON
+	resilience      config.Resilience
+}
+
+// sample returns a literal of a basic type for the i-th param or result or an empty string.
+func sample(f parser.Field, i int) string {
+	v, ok := sampleValues[f.Type]
+	if !ok {
+		return ""
+	}
+	if strings.Contains(v, "%s") {
+		return fmt.Sprintf(v, f.Name)
+	}
+	if strings.Contains(v, "%d") {
+		return fmt.Sprintf(v, i+1)
+	}
+	return v
+}
+
+// Generate generates the tests from the data of the transport they test, routes with codecs written
+// by hand are skipped.
+func (g *httpTestGenerator) Generate(result parser.Result) ([]File, error) {
+	transport := &httpGenerator{
+		cfg:             g.cfg,
+		zipkin:          g.zipkin,
+		logger:          g.logger,
+		jwt:             g.jwt,
+		client:          true,
+		genericRequest:  g.genericRequest,
+		genericResponse: g.genericResponse,
+		validate:        g.validate,
+		errors:          g.errors,
+		json:            g.json,
+		resilience:      g.resilience,
+	}
+	data, err := transport.data(result)
+	if err != nil {
+		return nil, err
+	}
+	src, err := renderTemplate("http_test.go.tmpl", g.templateDir, data)
+	if err != nil {
+		return nil, err
+	}
+	return []File{{Name: "http_test.go", Data: src}}, nil
+}
+
+func init() {
+	Register(Registration{
+		Name:      "http-test",
+		Command:   []string{"test", "http"},
+		Usage:     "generates round-trip tests of the http transport generated with the http flags of the config, the client must be generated",
+		Requires:  []string{"http"},
+		Transport: "http",
+		New: func(o Options) (Generator, error) {
+			flags := httpFlags(o.Config)
+			return NewHTTPTest(
+				HTTPTestGeneratorZipkin(flags["zipkin"]),
+				HTTPTestGeneratorLogger(flags["logger"]),
+				HTTPTestGeneratorJWT(flags["jwt"]),
+				HTTPTestGeneratorGenericRequest(flags["greq"]),
+				HTTPTestGeneratorGenericResponse(flags["gresp"]),
+				HTTPTestGeneratorConfig(o.HTTP),
+				HTTPTestGeneratorValidate(o.Config.Validate),
+				HTTPTestGeneratorErrors(o.Config.Endpoint.Errors),
+				HTTPTestGeneratorJSON(o.Config.JSON),
+				HTTPTestGeneratorResilience(o.Config.Resilience),
+				HTTPTestGeneratorTemplateDir(o.TemplateDir),
+			), nil
+		},
+	})
+}
+
+// httpFlags returns flags of the http generator from the config, flags of the http job of
+// the generate section override the generators section, as they do when it runs.
+func httpFlags(cfg config.Config) map[string]bool {
+	flags := map[string]bool{}
+	for name, v := range cfg.Generators["http"] {
+		flags[name] = v
+	}
+	for _, job := range cfg.Generate {
+		if job.Generator != "http" {
+			continue
+		}
+		for name, v := range job.Flags {
+			flags[name] = v
+		}
+		break
+	}
+	return flags
+}
+
+// NewHTTPTest creates a generator of round-trip tests of the http transport,
+// tests use the generated handler and client, so the client must be generated too.
+func NewHTTPTest(options ...HTTPTestGeneratorOption) Generator {
+	g := &httpTestGenerator{}
+	for _, o := range options {
+		o(g)
+	}
+	return g
+}
@@ -0,0 +1,65 @@
+package generators
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+)
+
+func TestHTTPFlags(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  config.Config
+		want map[string]bool
+	}{
+		{"none", config.Config{}, map[string]bool{}},
+		{
+			"generators section",
+			config.Config{Generators: map[string]map[string]bool{"http": {"zipkin": true}, "logging": {"st": true}}},
+			map[string]bool{"zipkin": true},
+		},
+		{
+			"generate job overrides",
+			config.Config{
+				Generators: map[string]map[string]bool{"http": {"zipkin": true, "c": true}},
+				Generate: []config.Generate{
+					{Generator: "endpoint", Flags: map[string]bool{"logger": true}},
+					{Generator: "http", Flags: map[string]bool{"zipkin": false, "logger": true}},
+				},
+			},
+			map[string]bool{"zipkin": false, "c": true, "logger": true},
+		},
+	}
+	for _, tc := range cases {
+		if got := httpFlags(tc.cfg); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestHTTPTestTransportFlags(t *testing.T) {
+	cases := []struct {
+		flags map[string]bool
+		args  string
+	}{
+		{nil, ""},
+		{map[string]bool{"zipkin": true}, ", tracer"},
+		{map[string]bool{"logger": true, "jwt": true}, ", log.NewNopLogger()"},
+		{map[string]bool{"zipkin": true, "logger": true, "gresp": true}, ", tracer, log.NewNopLogger()"},
+	}
+	for _, tc := range cases {
+		cfg := config.Config{Generators: map[string]map[string]bool{"http": tc.flags}}
+		files, err := generate("http-test", Options{Config: cfg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := files["http_test.go"]
+		for _, want := range []string{"NewHTTPHandler(svc" + tc.args + ")", "NewHTTPClient(s.URL" + tc.args + ", options...)"} {
+			if !strings.Contains(src, want) {
+				t.Errorf("flags %v: %q not found in\n%s", tc.flags, want, src)
+			}
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package generators
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+	"github.com/l-vitaly/gokitgen/pkg/utils"
+)
+
+var pathParamRegexp = regexp.MustCompile(`{([^{}]+)}`)
+
+// routers of the http handler.
+const (
+	HTTPRouterMux        = "mux"
+	HTTPRouterChi        = "chi"
+	HTTPRouterHTTPRouter = "httprouter"
+	HTTPRouterServeMux   = "servemux"
+)
+
+// httpRouter returns the router of the config, HTTPRouterMux by default.
+func httpRouter(cfg config.HTTPTransport) (string, error) {
+	switch cfg.Router {
+	case "":
+		return HTTPRouterMux, nil
+	case HTTPRouterMux, HTTPRouterChi, HTTPRouterHTTPRouter, HTTPRouterServeMux:
+		return cfg.Router, nil
+	}
+	return "", fmt.Errorf("http: unknown router %q, use %s, %s, %s or %s", cfg.Router,
+		HTTPRouterMux, HTTPRouterChi, HTTPRouterHTTPRouter, HTTPRouterServeMux)
+}
+
+// httpStatusNames names of the net/http status constants used in generated code.
+var httpStatusNames = map[int]string{
+	http.StatusOK:                  "http.StatusOK",
+	http.StatusCreated:             "http.StatusCreated",
+	http.StatusAccepted:            "http.StatusAccepted",
+	http.StatusNoContent:           "http.StatusNoContent",
+	http.StatusBadRequest:          "http.StatusBadRequest",
+	http.StatusUnauthorized:        "http.StatusUnauthorized",
+	http.StatusForbidden:           "http.StatusForbidden",
+	http.StatusNotFound:            "http.StatusNotFound",
+	http.StatusMethodNotAllowed:    "http.StatusMethodNotAllowed",
+	http.StatusConflict:            "http.StatusConflict",
+	http.StatusGone:                "http.StatusGone",
+	http.StatusPreconditionFailed:  "http.StatusPreconditionFailed",
+	http.StatusUnprocessableEntity: "http.StatusUnprocessableEntity",
+	http.StatusTooManyRequests:     "http.StatusTooManyRequests",
+	http.StatusInternalServerError: "http.StatusInternalServerError",
+	http.StatusNotImplemented:      "http.StatusNotImplemented",
+	http.StatusBadGateway:          "http.StatusBadGateway",
+	http.StatusServiceUnavailable:  "http.StatusServiceUnavailable",
+	http.StatusGatewayTimeout:      "http.StatusGatewayTimeout",
+}
+
+func httpStatus(code int) string {
+	if name, ok := httpStatusNames[code]; ok {
+		return name
+	}
+	return fmt.Sprint(code)
+}
+
+// HTTPError service error mapped to a http status code.
+type HTTPError struct {
+	Name string
+	Code int
+}
+
+// checkHTTPService reports services the http transport cannot be generated for.
+func checkHTTPService(result parser.Result) error {
+	if len(result.TypeParams) > 0 {
+		return fmt.Errorf("http: generic service %s is not supported, declare a service interface without type parameters using instantiated types", result.TypeString())
+	}
+	return nil
+}
+
+// httpErrors returns configured errors sorted by name, the generated ErrBadRequest is always first.
+func httpErrors(cfg config.HTTPTransport, prefix string) []HTTPError {
+	badRequest := ident(prefix, "ErrBadRequest")
+	errs := []HTTPError{{Name: badRequest, Code: http.StatusBadRequest}}
+	var names []string
+	for name := range cfg.Errors {
+		if name != badRequest {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, HTTPError{Name: name, Code: cfg.Errors[name]})
+	}
+	return errs
+}
+
+// HTTPRoute binding of an endpoint to a http route.
+type HTTPRoute struct {
+	Endpoint Endpoint
+	Method   string
+	Path     string
+	// Router router the route is registered in.
+	Router string
+	// Status success status code, responses of 204 No Content have no body.
+	Status int
+	// Configured reports whether the route is declared in the config,
+	// codecs of not configured routes are left to the user.
+	Configured  bool
+	PathParams  []EndpointTransportDataField
+	QueryParams []EndpointTransportDataField
+	BodyParams  []EndpointTransportDataField
+}
+
+// ClientPath returns the client request path, params are substituted by the request encoder.
+func (r HTTPRoute) ClientPath() string {
+	if len(r.PathParams) > 0 {
+		return ""
+	}
+	return r.Path
+}
+
+// RouterPath returns the path pattern of the router, params are declared as :name in httprouter.
+func (r HTTPRoute) RouterPath() string {
+	if r.Router != HTTPRouterHTTPRouter {
+		return r.Path
+	}
+	return pathParamRegexp.ReplaceAllString(r.Path, ":$1")
+}
+
+// PathVars returns a statement declaring the vars variable PathValue reads path params from, empty if there is none.
+func (r HTTPRoute) PathVars() string {
+	switch r.Router {
+	case HTTPRouterMux:
+		return "vars := mux.Vars(r)"
+	case HTTPRouterHTTPRouter:
+		return "vars := httprouter.ParamsFromContext(r.Context())"
+	}
+	return ""
+}
+
+// PathValue returns an expression of the path param of the request r.
+func (r HTTPRoute) PathValue(f EndpointTransportDataField) string {
+	name := f.Field.DeclaredName()
+	switch r.Router {
+	case HTTPRouterChi:
+		return fmt.Sprintf("chi.URLParam(r, %q)", name)
+	case HTTPRouterHTTPRouter:
+		return fmt.Sprintf("vars.ByName(%q)", name)
+	case HTTPRouterServeMux:
+		return fmt.Sprintf("r.PathValue(%q)", name)
+	}
+	return fmt.Sprintf("vars[%q]", name)
+}
+
+// EscapedPathValues reports whether PathValue returns escaped values the decoder unescapes,
+// chi and mux with UseEncodedPath match the escaped path, so params may contain slashes.
+func (r HTTPRoute) EscapedPathValues() bool {
+	return r.Router == HTTPRouterChi || r.Router == HTTPRouterMux
+}
+
+// PathExpr returns an expression building the path from the fields of the req variable.
+func (r HTTPRoute) PathExpr() string {
+	return r.pathExpr(false)
+}
+
+// EscapedPathExpr returns an expression building the escaped path from the fields of the req variable,
+// every param is escaped with url.PathEscape.
+func (r HTTPRoute) EscapedPathExpr() string {
+	return r.pathExpr(true)
+}
+
+func (r HTTPRoute) pathExpr(escape bool) string {
+	params := map[string]EndpointTransportDataField{}
+	for _, f := range r.PathParams {
+		params[f.Field.DeclaredName()] = f
+	}
+	var parts []string
+	last := 0
+	for _, loc := range pathParamRegexp.FindAllStringSubmatchIndex(r.Path, -1) {
+		if loc[0] > last {
+			parts = append(parts, fmt.Sprintf("%q", r.Path[last:loc[0]]))
+		}
+		f := params[r.Path[loc[2]:loc[3]]]
+		part := fmt.Sprintf(stringConverters[f.Field.Type].Format, "req."+f.Name)
+		if escape {
+			part = "url.PathEscape(" + part + ")"
+		}
+		parts = append(parts, part)
+		last = loc[1]
+	}
+	if last < len(r.Path) {
+		parts = append(parts, fmt.Sprintf("%q", r.Path[last:]))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// HasParams reports whether any request param is sent over http.
+func (r HTTPRoute) HasParams() bool {
+	return len(r.PathParams)+len(r.QueryParams)+len(r.BodyParams) > 0
+}
+
+func newHTTPRoutes(endpoints []Endpoint, cfg config.HTTPTransport) ([]HTTPRoute, error) {
+	router, err := httpRouter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var routes []HTTPRoute
+	for _, e := range endpoints {
+		route := HTTPRoute{
+			Endpoint: e,
+			Method:   http.MethodPost,
+			Path:     "/" + utils.KebabCase(e.Method.Name),
+			Router:   router,
+			Status:   http.StatusOK,
+		}
+		if len(e.Results()) == 0 {
+			route.Status = http.StatusNoContent
+		}
+
+		epCfg, ok := cfg.Endpoints[e.Method.Name]
+		if !ok {
+			routes = append(routes, route)
+			continue
+		}
+		route.Configured = true
+		if epCfg.Status != 0 {
+			if epCfg.Status < 200 || epCfg.Status > 299 {
+				return nil, fmt.Errorf("http: %s status %d is not a success status", e.Method.Name, epCfg.Status)
+			}
+			if epCfg.Status == http.StatusNoContent && len(e.Results()) > 0 {
+				return nil, fmt.Errorf("http: %s status %d cannot carry the results", e.Method.Name, epCfg.Status)
+			}
+			route.Status = epCfg.Status
+		}
+		if epCfg.Method != "" {
+			route.Method = strings.ToUpper(epCfg.Method)
+		}
+		if epCfg.Path != "" {
+			route.Path = epCfg.Path
+		}
+
+		params := map[string]EndpointTransportDataField{}
+		for _, f := range e.Request.Fields {
+			if !f.Field.IsContext() {
+				params[f.Field.DeclaredName()] = f
+			}
+		}
+		bound := map[string]bool{}
+		bind := func(name, place string) (EndpointTransportDataField, error) {
+			f, ok := params[name]
+			if !ok {
+				return f, fmt.Errorf("http: %s %s param %q not found", e.Method.Name, place, name)
+			}
+			if bound[name] {
+				return f, fmt.Errorf("http: %s param %q bound twice", e.Method.Name, name)
+			}
+			bound[name] = true
+			return f, nil
+		}
+
+		for _, m := range pathParamRegexp.FindAllStringSubmatch(route.Path, -1) {
+			f, err := bind(m[1], "path")
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := stringConverters[f.Field.Type]; !ok {
+				return nil, fmt.Errorf("http: %s path param %q has unsupported type %s", e.Method.Name, m[1], f.Field.Type)
+			}
+			route.PathParams = append(route.PathParams, f)
+		}
+		for _, name := range epCfg.Query {
+			f, err := bind(name, "query")
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := stringConverters[f.Field.Type]; !ok {
+				return nil, fmt.Errorf("http: %s query param %q has unsupported type %s", e.Method.Name, name, f.Field.Type)
+			}
+			route.QueryParams = append(route.QueryParams, f)
+		}
+		if epCfg.Body != nil {
+			for _, name := range epCfg.Body {
+				f, err := bind(name, "body")
+				if err != nil {
+					return nil, err
+				}
+				route.BodyParams = append(route.BodyParams, f)
+			}
+		} else {
+			for _, f := range e.Request.Fields {
+				if _, ok := params[f.Field.DeclaredName()]; ok && !bound[f.Field.DeclaredName()] {
+					route.BodyParams = append(route.BodyParams, f)
+				}
+			}
+		}
+
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// stringConverter parses and formats values of a basic type kept in a path or query string.
+type stringConverter struct {
+	// Parse expression with a single %s placeholder for the string value,
+	// it must return the value and an error.
+	Parse string
+	// Format expression with a single %s placeholder for the value.
+	Format string
+	// Result type of the parsed value.
+	Result string
+}
+
+var stringConverters = map[string]stringConverter{
+	"string":  {Format: "%s"},
+	"bool":    {Parse: "strconv.ParseBool(%s)", Format: "strconv.FormatBool(%s)", Result: "bool"},
+	"int":     {Parse: "strconv.ParseInt(%s, 10, 0)", Format: "strconv.FormatInt(int64(%s), 10)", Result: "int64"},
+	"int8":    {Parse: "strconv.ParseInt(%s, 10, 8)", Format: "strconv.FormatInt(int64(%s), 10)", Result: "int64"},
+	"int16":   {Parse: "strconv.ParseInt(%s, 10, 16)", Format: "strconv.FormatInt(int64(%s), 10)", Result: "int64"},
+	"int32":   {Parse: "strconv.ParseInt(%s, 10, 32)", Format: "strconv.FormatInt(int64(%s), 10)", Result: "int64"},
+	"int64":   {Parse: "strconv.ParseInt(%s, 10, 64)", Format: "strconv.FormatInt(%s, 10)", Result: "int64"},
+	"uint":    {Parse: "strconv.ParseUint(%s, 10, 0)", Format: "strconv.FormatUint(uint64(%s), 10)", Result: "uint64"},
+	"uint8":   {Parse: "strconv.ParseUint(%s, 10, 8)", Format: "strconv.FormatUint(uint64(%s), 10)", Result: "uint64"},
+	"uint16":  {Parse: "strconv.ParseUint(%s, 10, 16)", Format: "strconv.FormatUint(uint64(%s), 10)", Result: "uint64"},
+	"uint32":  {Parse: "strconv.ParseUint(%s, 10, 32)", Format: "strconv.FormatUint(uint64(%s), 10)", Result: "uint64"},
+	"uint64":  {Parse: "strconv.ParseUint(%s, 10, 64)", Format: "strconv.FormatUint(%s, 10)", Result: "uint64"},
+	"float32": {Parse: "strconv.ParseFloat(%s, 32)", Format: "strconv.FormatFloat(float64(%s), 'g', -1, 32)", Result: "float64"},
+	"float64": {Parse: "strconv.ParseFloat(%s, 64)", Format: "strconv.FormatFloat(%s, 'g', -1, 64)", Result: "float64"},
+}
@@ -0,0 +1,294 @@
+package generators
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestHTTPRouter(t *testing.T) {
+	cases := []struct {
+		router, want, err string
+	}{
+		{"", HTTPRouterMux, ""},
+		{HTTPRouterMux, HTTPRouterMux, ""},
+		{HTTPRouterChi, HTTPRouterChi, ""},
+		{HTTPRouterHTTPRouter, HTTPRouterHTTPRouter, ""},
+		{HTTPRouterServeMux, HTTPRouterServeMux, ""},
+		{"gin", "", `unknown router "gin"`},
+	}
+	for _, tc := range cases {
+		got, err := httpRouter(config.HTTPTransport{Router: tc.router})
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("httpRouter(%q): got error %v, want %q", tc.router, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("httpRouter(%q): got %q, %v, want %q", tc.router, got, err, tc.want)
+		}
+	}
+}
+
+// testHTTPConfig binds the path and query params of the test service.
+func testHTTPConfig(router string) config.HTTPTransport {
+	return config.HTTPTransport{
+		Router: router,
+		Endpoints: map[string]config.HTTPEndpoint{
+			"Say": {Method: "get", Path: "/say/{name}"},
+			"Get": {Path: "/items/{id}", Query: []string{"verbose"}},
+		},
+	}
+}
+
+func TestHTTPRoutePaths(t *testing.T) {
+	cases := []struct {
+		router      string
+		routerPath  string
+		pathVars    string
+		pathValue   string
+		escapedVals bool
+	}{
+		{HTTPRouterMux, "/items/{id}", "vars := mux.Vars(r)", `vars["id"]`, true},
+		{HTTPRouterChi, "/items/{id}", "", `chi.URLParam(r, "id")`, true},
+		{HTTPRouterHTTPRouter, "/items/:id", "vars := httprouter.ParamsFromContext(r.Context())", `vars.ByName("id")`, false},
+		{HTTPRouterServeMux, "/items/{id}", "", `r.PathValue("id")`, false},
+	}
+	for _, tc := range cases {
+		routes, err := newHTTPRoutes(newData(testResult(), nil).Endpoints, testHTTPConfig(tc.router))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := routes[1]
+		if got := r.RouterPath(); got != tc.routerPath {
+			t.Errorf("%s: RouterPath: got %q, want %q", tc.router, got, tc.routerPath)
+		}
+		if got := r.PathVars(); got != tc.pathVars {
+			t.Errorf("%s: PathVars: got %q, want %q", tc.router, got, tc.pathVars)
+		}
+		if got := r.PathValue(r.PathParams[0]); got != tc.pathValue {
+			t.Errorf("%s: PathValue: got %q, want %q", tc.router, got, tc.pathValue)
+		}
+		if got := r.EscapedPathValues(); got != tc.escapedVals {
+			t.Errorf("%s: EscapedPathValues: got %v, want %v", tc.router, got, tc.escapedVals)
+		}
+		if got, want := r.PathExpr(), `"/items/" + strconv.FormatInt(int64(req.ID), 10)`; got != want {
+			t.Errorf("%s: PathExpr: got %q, want %q", tc.router, got, want)
+		}
+		if got, want := r.EscapedPathExpr(), `"/items/" + url.PathEscape(strconv.FormatInt(int64(req.ID), 10))`; got != want {
+			t.Errorf("%s: EscapedPathExpr: got %q, want %q", tc.router, got, want)
+		}
+		if got := routes[0].ClientPath(); got != "" {
+			t.Errorf("%s: ClientPath of a path with params: got %q", tc.router, got)
+		}
+	}
+}
+
+func TestNewHTTPRoutesParams(t *testing.T) {
+	cases := []struct {
+		name     string
+		endpoint config.HTTPEndpoint
+		method   string
+		path     string
+		params   [3]string // path, query and body params
+		err      string
+	}{
+		{
+			name:   "default",
+			method: "POST",
+			path:   "/get",
+			params: [3]string{"", "", "id verbose"},
+		},
+		{
+			name:     "path and query",
+			endpoint: config.HTTPEndpoint{Method: "get", Path: "/items/{id}", Query: []string{"verbose"}},
+			method:   "GET",
+			path:     "/items/{id}",
+			params:   [3]string{"id", "verbose", ""},
+		},
+		{
+			name:     "explicit body",
+			endpoint: config.HTTPEndpoint{Path: "/items/{id}", Body: []string{"verbose"}},
+			method:   "POST",
+			path:     "/items/{id}",
+			params:   [3]string{"id", "", "verbose"},
+		},
+		{
+			name:     "empty body",
+			endpoint: config.HTTPEndpoint{Query: []string{"id"}, Body: []string{}},
+			method:   "POST",
+			path:     "/get",
+			params:   [3]string{"", "id", ""},
+		},
+		{
+			name:     "unknown param",
+			endpoint: config.HTTPEndpoint{Path: "/items/{key}"},
+			err:      `Get path param "key" not found`,
+		},
+		{
+			name:     "bound twice",
+			endpoint: config.HTTPEndpoint{Path: "/items/{id}", Query: []string{"id"}},
+			err:      `Get param "id" bound twice`,
+		},
+	}
+	for _, tc := range cases {
+		cfg := config.HTTPTransport{Endpoints: map[string]config.HTTPEndpoint{"Get": tc.endpoint}}
+		routes, err := newHTTPRoutes(newData(testResult(), nil).Endpoints, cfg)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: got error %v, want %q", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		r := routes[1]
+		if r.Method != tc.method || r.Path != tc.path {
+			t.Errorf("%s: got %s %s, want %s %s", tc.name, r.Method, r.Path, tc.method, tc.path)
+		}
+		for i, fields := range [][]EndpointTransportDataField{r.PathParams, r.QueryParams, r.BodyParams} {
+			var names []string
+			for _, f := range fields {
+				names = append(names, f.Field.Name)
+			}
+			if got := strings.Join(names, " "); got != tc.params[i] {
+				t.Errorf("%s: params %d: got %q, want %q", tc.name, i, got, tc.params[i])
+			}
+		}
+	}
+}
+
+func TestNewHTTPRoutesUnsupportedType(t *testing.T) {
+	result := testResult()
+	result.Methods[1].Params[1].Type = "[]int"
+	for _, endpoint := range []config.HTTPEndpoint{{Path: "/items/{id}"}, {Query: []string{"id"}}} {
+		cfg := config.HTTPTransport{Endpoints: map[string]config.HTTPEndpoint{"Get": endpoint}}
+		_, err := newHTTPRoutes(newData(result, nil).Endpoints, cfg)
+		if err == nil || !strings.Contains(err.Error(), "has unsupported type []int") {
+			t.Errorf("%+v: got error %v, want unsupported type", endpoint, err)
+		}
+	}
+}
+
+// TestHTTPTransportRouters compares the transport generated for every router with the golden files,
+// run the tests with -update to update them.
+func TestHTTPTransportRouters(t *testing.T) {
+	for _, router := range []string{HTTPRouterMux, HTTPRouterChi, HTTPRouterHTTPRouter, HTTPRouterServeMux} {
+		files, err := generate("http", Options{Flags: map[string]bool{"c": true}, HTTP: testHTTPConfig(router)})
+		if err != nil {
+			t.Fatalf("%s: %v", router, err)
+		}
+		golden := filepath.Join("testdata", "http_"+router+".golden")
+		if *update {
+			if err := ioutil.WriteFile(golden, []byte(files["http_gen.go"]), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files["http_gen.go"] != string(want) {
+			t.Errorf("%s: the generated transport differs from %s, run the tests with -update if the change is intended", router, golden)
+		}
+	}
+}
+
+func TestHTTPGenericService(t *testing.T) {
+	want := "http: generic service Service[K, V] is not supported"
+	for _, name := range []string{"http", "http-test"} {
+		r, _ := Lookup(name)
+		g, err := r.New(Options{HTTP: testHTTPConfig("")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.Generate(genericResult()); err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", name, err, want)
+		}
+	}
+}
+
+func TestNewHTTPRoutesStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		say, get int
+		want     [2]int
+		err      string
+	}{
+		{name: "default", want: [2]int{200, 204}},
+		{name: "configured", say: 201, get: 202, want: [2]int{201, 202}},
+		{name: "body of no results", get: 200, want: [2]int{200, 200}},
+		{name: "no content with results", say: 204, err: "http: Say status 204 cannot carry the results"},
+		{name: "error status", get: 404, err: "http: Get status 404 is not a success status"},
+		{name: "informational status", get: 101, err: "http: Get status 101 is not a success status"},
+	}
+	for _, tc := range cases {
+		cfg := config.HTTPTransport{Endpoints: map[string]config.HTTPEndpoint{
+			"Say": {Status: tc.say},
+			"Get": {Status: tc.get},
+		}}
+		routes, err := newHTTPRoutes(newData(testResult(), nil).Endpoints, cfg)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%s: got error %v, want %q", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if got := [2]int{routes[0].Status, routes[1].Status}; got != tc.want {
+			t.Errorf("%s: got statuses %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	cases := []struct {
+		code int
+		want string
+	}{
+		{200, "http.StatusOK"},
+		{201, "http.StatusCreated"},
+		{204, "http.StatusNoContent"},
+		{207, "207"},
+	}
+	for _, tc := range cases {
+		if got := httpStatus(tc.code); got != tc.want {
+			t.Errorf("httpStatus(%d): got %q, want %q", tc.code, got, tc.want)
+		}
+	}
+}
+
+func TestHTTPTransportStatus(t *testing.T) {
+	cfg := testHTTPConfig(HTTPRouterMux)
+	say := cfg.Endpoints["Say"]
+	say.Status = 201
+	cfg.Endpoints["Say"] = say
+	files, err := generate("http", Options{Flags: map[string]bool{"c": true}, HTTP: cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := files["http_gen.go"]
+	for _, s := range []string{
+		"encodeHTTPGenericResponse(http.StatusCreated)",
+		"encodeHTTPGenericResponse(http.StatusNoContent)",
+		"if r.StatusCode != http.StatusCreated {",
+		"if r.StatusCode != http.StatusNoContent {",
+	} {
+		if !strings.Contains(src, s) {
+			t.Errorf("%q not found in\n%s", s, src)
+		}
+	}
+}
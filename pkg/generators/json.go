@@ -0,0 +1,122 @@
+package generators
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/utils"
+)
+
+// JSON naming strategies of the request and response fields.
+const (
+	JSONNamingGo    = "go"
+	JSONNamingCamel = "camel"
+	JSONNamingSnake = "snake"
+)
+
+// jsonName returns the wire name of the field by the naming strategy, an empty name keeps the struct field name.
+func jsonName(f EndpointTransportDataField, naming string) string {
+	switch naming {
+	case JSONNamingCamel:
+		words := utils.Words(f.Field.DeclaredName())
+		for i, w := range words {
+			w = strings.ToLower(w)
+			if i > 0 {
+				w = utils.UcFirst(w)
+			}
+			words[i] = w
+		}
+		return strings.Join(words, "")
+	case JSONNamingSnake:
+		return utils.SnakeCase(f.Field.DeclaredName())
+	}
+	return ""
+}
+
+// jsonTags sets tags of the request and response fields of the endpoints, error fields are never encoded.
+func jsonTags(endpoints []Endpoint, cfg config.JSON) error {
+	switch cfg.Naming {
+	case "", JSONNamingGo, JSONNamingCamel, JSONNamingSnake:
+	default:
+		return fmt.Errorf("json: unknown naming %q, use %s, %s or %s", cfg.Naming, JSONNamingGo, JSONNamingCamel, JSONNamingSnake)
+	}
+	methods := map[string]bool{}
+	for i := range endpoints {
+		e := &endpoints[i]
+		methods[e.Method.Name] = true
+		fields := cfg.Fields[e.Method.Name]
+		used := map[string]bool{}
+		for _, data := range []*EndpointTransportData{&e.Request, &e.Response} {
+			names := map[string]string{}
+			for j := range data.Fields {
+				f := &data.Fields[j]
+				if f.Field.IsContext() {
+					continue
+				}
+				tag, ok := fields[f.Field.DeclaredName()]
+				used[f.Field.DeclaredName()] = used[f.Field.DeclaredName()] || ok
+				if f.Field.IsError() {
+					if ok {
+						return fmt.Errorf("json: %s %s is an error, errors are not encoded", e.Method.Name, f.Field.DeclaredName())
+					}
+					f.Tag = `json:"-"`
+					continue
+				}
+				name, opts := tag, ""
+				if n := strings.IndexByte(tag, ','); n >= 0 {
+					// a trailing comma only matters in "-,", which names the field "-" as encoding/json does
+					name, opts = tag[:n], tag[n:]
+					if name != "-" {
+						opts = strings.TrimSuffix(opts, ",")
+					}
+				} else if cfg.OmitEmpty {
+					opts = ",omitempty"
+				}
+				if strings.ContainsAny(tag, "\"`\\") {
+					return fmt.Errorf("json: %s %s has invalid tag %q", e.Method.Name, f.Field.DeclaredName(), tag)
+				}
+				if name == "" {
+					name = jsonName(*f, cfg.Naming)
+				}
+				if tag == "-" {
+					f.Tag = `json:"-"`
+					continue
+				}
+				wire := name
+				if wire == "" {
+					wire = f.Name
+				}
+				if other, ok := names[wire]; ok {
+					return fmt.Errorf("json: %s %s and %s have the same name %q", e.Method.Name, other, f.Field.DeclaredName(), wire)
+				}
+				names[wire] = f.Field.DeclaredName()
+				if name != "" || opts != "" {
+					f.Tag = fmt.Sprintf(`json:"%s%s"`, name, opts)
+				}
+			}
+		}
+		var unknown []string
+		for name := range fields {
+			if !used[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return fmt.Errorf("json: %s has no params or results %s", e.Method.Name, strings.Join(unknown, ", "))
+		}
+	}
+	var unknown []string
+	for name := range cfg.Fields {
+		if !methods[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("json: unknown methods %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
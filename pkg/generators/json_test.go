@@ -0,0 +1,117 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+)
+
+func TestJSONName(t *testing.T) {
+	cases := []struct {
+		field, naming, want string
+	}{
+		{"userID", JSONNamingGo, ""},
+		{"userID", JSONNamingCamel, "userId"},
+		{"userID", JSONNamingSnake, "user_id"},
+		{"HTTPServer", JSONNamingCamel, "httpServer"},
+		{"HTTPServer", JSONNamingSnake, "http_server"},
+	}
+	for _, tc := range cases {
+		f := EndpointTransportDataField{Field: parser.Field{Name: tc.field}}
+		if got := jsonName(f, tc.naming); got != tc.want {
+			t.Errorf("jsonName(%q, %s): got %q, want %q", tc.field, tc.naming, got, tc.want)
+		}
+	}
+}
+
+func TestJSONTags(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  config.JSON
+		// tags tags by Method.field, fields not listed have no tag, errors always have json:"-"
+		tags map[string]string
+		err  string
+	}{
+		{name: "default"},
+		{
+			name: "snake naming",
+			cfg:  config.JSON{Naming: JSONNamingSnake},
+			tags: map[string]string{"Say.name": `json:"name"`, "Say.message": `json:"message"`, "Get.id": `json:"id"`, "Get.verbose": `json:"verbose"`},
+		},
+		{
+			name: "omitempty",
+			cfg:  config.JSON{OmitEmpty: true},
+			tags: map[string]string{"Say.name": `json:",omitempty"`, "Say.message": `json:",omitempty"`, "Get.id": `json:",omitempty"`, "Get.verbose": `json:",omitempty"`},
+		},
+		{
+			name: "field tags",
+			cfg: config.JSON{OmitEmpty: true, Fields: map[string]map[string]string{
+				"Say": {"name": "full_name", "message": "msg,"},
+				"Get": {"id": "-", "verbose": "-,"},
+			}},
+			tags: map[string]string{"Say.name": `json:"full_name,omitempty"`, "Say.message": `json:"msg"`, "Get.id": `json:"-"`, "Get.verbose": `json:"-,"`},
+		},
+		{
+			name: "options keep the wire name of the naming",
+			cfg:  config.JSON{Naming: JSONNamingCamel, Fields: map[string]map[string]string{"Get": {"verbose": ",string"}}},
+			tags: map[string]string{"Say.name": `json:"name"`, "Say.message": `json:"message"`, "Get.id": `json:"id"`, "Get.verbose": `json:"verbose,string"`},
+		},
+		{
+			name: "unknown naming",
+			cfg:  config.JSON{Naming: "kebab"},
+			err:  `unknown naming "kebab"`,
+		},
+		{
+			name: "error tag",
+			cfg:  config.JSON{Fields: map[string]map[string]string{"Say": {"err": "error"}}},
+			err:  "Say err is an error",
+		},
+		{
+			name: "invalid tag",
+			cfg:  config.JSON{Fields: map[string]map[string]string{"Say": {"name": `na"me`}}},
+			err:  "invalid tag",
+		},
+		{
+			name: "same wire names",
+			cfg:  config.JSON{Fields: map[string]map[string]string{"Get": {"id": "Verbose"}, "Say": {}}},
+			err:  `Get id and verbose have the same name "Verbose"`,
+		},
+		{
+			name: "unknown field",
+			cfg:  config.JSON{Fields: map[string]map[string]string{"Say": {"nope": "x"}}},
+			err:  "Say has no params or results nope",
+		},
+		{
+			name: "unknown method",
+			cfg:  config.JSON{Fields: map[string]map[string]string{"Nope": {}}},
+			err:  "unknown methods Nope",
+		},
+	}
+	for _, tc := range cases {
+		endpoints := newData(testResult(), nil).Endpoints
+		err := jsonTags(endpoints, tc.cfg)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: got error %v, want %q", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		for _, e := range endpoints {
+			for _, f := range append(e.Request.Fields, e.Response.Fields...) {
+				want := tc.tags[e.Method.Name+"."+f.Field.Name]
+				if f.Field.IsError() {
+					want = `json:"-"`
+				}
+				if f.Tag != want {
+					t.Errorf("%s: %s %s: got tag %q, want %q", tc.name, e.Method.Name, f.Field.Name, f.Tag, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+)
+
+// PluginProtocolVersion version of the plugin protocol, it is increased on incompatible changes.
+const PluginProtocolVersion = 1
+
+// PluginRequest is written as JSON to the plugin stdin.
+type PluginRequest struct {
+	Version int `json:"version"`
+	// Name plugin name.
+	Name   string        `json:"name"`
+	Result parser.Result `json:"result"`
+	// HTTP http transport config.
+	HTTP config.HTTPTransport `json:"http"`
+	// Options plugin options from the config.
+	Options map[string]interface{} `json:"options"`
+}
+
+// PluginFile file returned by a plugin.
+type PluginFile struct {
+	// Name file name relative to the output dir.
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// PluginDiagnostic message reported by a plugin, the run fails on errors.
+type PluginDiagnostic struct {
+	// Severity "error" or "warning".
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// File optional file name the message refers to.
+	File string `json:"file,omitempty"`
+	// Line optional line of the file.
+	Line int `json:"line,omitempty"`
+}
+
+func (d PluginDiagnostic) String() string {
+	switch {
+	case d.File != "" && d.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+	case d.File != "":
+		return fmt.Sprintf("%s: %s", d.File, d.Message)
+	}
+	return d.Message
+}
+
+// PluginResponse is read as JSON from the plugin stdout.
+type PluginResponse struct {
+	Files       []PluginFile       `json:"files"`
+	Diagnostics []PluginDiagnostic `json:"diagnostics"`
+}
+
+// PluginGeneratorOption plugin generator option.
+type PluginGeneratorOption func(g *PluginGenerator)
+
+// PluginGeneratorPath plugin binary, gokitgen-<name> looked up in PATH by default.
+func PluginGeneratorPath(path string) PluginGeneratorOption {
+	return func(g *PluginGenerator) {
+		g.path = path
+	}
+}
+
+// PluginGeneratorOptions options passed to the plugin.
+func PluginGeneratorOptions(options map[string]interface{}) PluginGeneratorOption {
+	return func(g *PluginGenerator) {
+		g.options = options
+	}
+}
+
+// PluginGeneratorHTTPConfig http transport config passed to the plugin.
+func PluginGeneratorHTTPConfig(cfg config.HTTPTransport) PluginGeneratorOption {
+	return func(g *PluginGenerator) {
+		g.httpCfg = cfg
+	}
+}
+
+// PluginGenerator runs an external generator binary. The binary reads
+// PluginRequest from stdin and writes PluginResponse to stdout, its stderr
+// is passed through.
+type PluginGenerator struct {
+	name    string
+	path    string
+	options map[string]interface{}
+	httpCfg config.HTTPTransport
+}
+
+// Generate runs the plugin and returns its files.
+func (g *PluginGenerator) Generate(result parser.Result) ([]File, error) {
+	path := g.path
+	if path == "" {
+		var err error
+		if path, err = exec.LookPath("gokitgen-" + g.name); err != nil {
+			return nil, fmt.Errorf("plugin %s: %v", g.name, err)
+		}
+	}
+
+	req, err := json.Marshal(PluginRequest{
+		Version: PluginProtocolVersion,
+		Name:    g.name,
+		Result:  result,
+		HTTP:    g.httpCfg,
+		Options: jsonValue(g.options).(map[string]interface{}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(path)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s: %v", g.name, err)
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid response: %v", g.name, err)
+	}
+
+	var errs []string
+	for _, d := range resp.Diagnostics {
+		if d.Severity == "error" {
+			errs = append(errs, d.String())
+		} else {
+			log.Printf("plugin %s: %s", g.name, d)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("plugin %s:\n%s", g.name, strings.Join(errs, "\n"))
+	}
+
+	files := make([]File, 0, len(resp.Files))
+	for _, f := range resp.Files {
+		name := filepath.Clean(filepath.FromSlash(f.Name))
+		if f.Name == "" || filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("plugin %s: file name %q is not relative to the output dir", g.name, f.Name)
+		}
+		files = append(files, File{Name: name, Data: []byte(f.Content)})
+	}
+	return files, nil
+}
+
+// jsonValue converts maps decoded from YAML, which have interface{} keys, to maps JSON can encode.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = jsonValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = jsonValue(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = jsonValue(val)
+		}
+		return s
+	}
+	return v
+}
+
+func init() {
+	Register(Registration{
+		Name:      "plugin",
+		Command:   []string{"plugin"},
+		Usage:     "runs an external generator, gokitgen-<name> in PATH or the binary declared in the config",
+		ArgsUsage: "<name>",
+		New: func(o Options) (Generator, error) {
+			if len(o.Args) == 0 {
+				return nil, fmt.Errorf("plugin name is required")
+			}
+			name := o.Args[0]
+			p := o.Config.Plugins[name]
+			return NewPlugin(
+				name,
+				PluginGeneratorPath(p.Path),
+				PluginGeneratorOptions(p.Options),
+				PluginGeneratorHTTPConfig(o.HTTP),
+			), nil
+		},
+	})
+}
+
+// NewPlugin creates a generator running the plugin binary of the name.
+func NewPlugin(name string, options ...PluginGeneratorOption) *PluginGenerator {
+	g := &PluginGenerator{name: name}
+	for _, o := range options {
+		o(g)
+	}
+	return g
+}
@@ -0,0 +1,154 @@
+package generators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+)
+
+// TestMain runs the test binary as a fake plugin when GOKITGEN_TEST_PLUGIN is set
+// to the plugin mode, see fakePlugin.
+func TestMain(m *testing.M) {
+	if mode := os.Getenv("GOKITGEN_TEST_PLUGIN"); mode != "" {
+		os.Exit(fakePlugin(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// fakePlugin reads the plugin request and answers according to the mode,
+// the files mode echoes the request in the file content.
+func fakePlugin(mode string) int {
+	var req PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var resp PluginResponse
+	switch mode {
+	case "files":
+		resp.Files = []PluginFile{
+			{Name: "plugin.txt", Content: fmt.Sprintf("%d %s %s %d %v", req.Version, req.Name, req.Result.ServiceName, len(req.HTTP.Endpoints), req.Options)},
+			{Name: "sub/../nested/plugin.txt", Content: "nested"},
+		}
+	case "error":
+		resp.Files = []PluginFile{{Name: "plugin.txt"}}
+		resp.Diagnostics = []PluginDiagnostic{
+			{Severity: "warning", Message: "deprecated option"},
+			{Severity: "error", Message: "unsupported type", File: "service.go", Line: 12},
+			{Severity: "error", Message: "no methods", File: "service.go"},
+		}
+	case "warning":
+		resp.Files = []PluginFile{{Name: "plugin.txt"}}
+		resp.Diagnostics = []PluginDiagnostic{{Severity: "warning", Message: "deprecated option", File: "service.go", Line: 3}}
+	case "absolute":
+		resp.Files = []PluginFile{{Name: "/etc/plugin.txt"}}
+	case "parent":
+		resp.Files = []PluginFile{{Name: "../plugin.txt"}}
+	case "invalid":
+		fmt.Print("not json")
+		return 0
+	case "exit":
+		return 3
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func TestPluginGenerator(t *testing.T) {
+	cfg := config.Config{Plugins: map[string]config.Plugin{
+		"fake": {
+			Path: os.Args[0],
+			Options: map[string]interface{}{
+				"greeting": "hi",
+				"nested":   map[interface{}]interface{}{"count": 1},
+			},
+		},
+	}}
+	cases := []struct {
+		mode  string
+		files map[string]string
+		log   string
+		err   string
+	}{
+		{
+			mode: "files",
+			files: map[string]string{
+				"plugin.txt":        "1 fake Service 2 map[greeting:hi nested:map[count:1]]",
+				"nested/plugin.txt": "nested",
+			},
+		},
+		{
+			mode:  "warning",
+			files: map[string]string{"plugin.txt": ""},
+			log:   "plugin fake: service.go:3: deprecated option",
+		},
+		{mode: "error", err: "plugin fake:\nservice.go:12: unsupported type\nservice.go: no methods", log: "plugin fake: deprecated option"},
+		{mode: "absolute", err: `plugin fake: file name "/etc/plugin.txt" is not relative to the output dir`},
+		{mode: "parent", err: `plugin fake: file name "../plugin.txt" is not relative to the output dir`},
+		{mode: "invalid", err: "plugin fake: invalid response: "},
+		{mode: "exit", err: "plugin fake: exit status 3"},
+	}
+	defer log.SetOutput(os.Stderr)
+	for _, tc := range cases {
+		t.Setenv("GOKITGEN_TEST_PLUGIN", tc.mode)
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+
+		files, err := generate("plugin", Options{Args: []string{"fake"}, HTTP: testHTTPConfig(""), Config: cfg})
+		if !strings.Contains(buf.String(), tc.log) {
+			t.Errorf("%s: %q not found in the log:\n%s", tc.mode, tc.log, buf.String())
+		}
+		if tc.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+				t.Errorf("%s: got error %v, want %q", tc.mode, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.mode, err)
+			continue
+		}
+		if !reflect.DeepEqual(files, tc.files) {
+			t.Errorf("%s: got files %q, want %q", tc.mode, files, tc.files)
+		}
+	}
+}
+
+func TestPluginGeneratorNotFound(t *testing.T) {
+	t.Setenv("PATH", "")
+	_, err := generate("plugin", Options{Args: []string{"missing"}})
+	if err == nil || !strings.HasPrefix(err.Error(), `plugin missing: exec: "gokitgen-missing"`) {
+		t.Errorf("got error %v, want the plugin not found in PATH", err)
+	}
+	if _, err := generate("plugin", Options{}); err == nil || err.Error() != "plugin name is required" {
+		t.Errorf("no name: got error %v, want the name required", err)
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	in := map[string]interface{}{
+		"name": "hi",
+		"map":  map[interface{}]interface{}{1: "one", "list": []interface{}{map[interface{}]interface{}{true: "yes"}}},
+	}
+	want := map[string]interface{}{
+		"name": "hi",
+		"map":  map[string]interface{}{"1": "one", "list": []interface{}{map[string]interface{}{"true": "yes"}}},
+	}
+	got := jsonValue(in)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("jsonValue: got %v, want %v", got, want)
+	}
+	if _, err := json.Marshal(got); err != nil {
+		t.Errorf("jsonValue: the value is not encoded: %v", err)
+	}
+}
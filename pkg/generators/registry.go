@@ -0,0 +1,111 @@
+package generators
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+)
+
+// Flag boolean generator option, it is set by a CLI flag of the generator
+// command or in the generators section of the config.
+type Flag struct {
+	Name  string
+	Usage string
+}
+
+// Options options a registered generator is created with.
+type Options struct {
+	// Flags values of the generator flags.
+	Flags map[string]bool
+	// Args command arguments, e.g. the template generator name.
+	Args []string
+	// TemplateDir directory with templates overriding the built-in ones.
+	TemplateDir string
+	// HTTP http transport config.
+	HTTP   config.HTTPTransport
+	Config config.Config
+}
+
+// Registration generator registration.
+type Registration struct {
+	// Name generator name, the key of its options in the config.
+	Name string
+	// Command command path, e.g. ["transport", "http"].
+	Command   []string
+	Aliases   []string
+	Usage     string
+	ArgsUsage string
+	Flags     []Flag
+	// Requires names of generators the generated code depends on.
+	Requires []string
+	// Transport transport of the generated code, services limited to other
+	// transports by the //gokit:service directive are skipped.
+	Transport string
+	// New creates the generator.
+	New func(o Options) (Generator, error)
+}
+
+var registry = map[string]Registration{}
+
+// Register registers a generator, it panics if the name is taken.
+func Register(r Registration) {
+	if _, ok := registry[r.Name]; ok {
+		panic("generators: " + r.Name + " is already registered")
+	}
+	registry[r.Name] = r
+}
+
+// Lookup returns the registered generator.
+func Lookup(name string) (Registration, bool) {
+	r, ok := registry[name]
+	return r, ok
+}
+
+// Registered returns registered generators sorted by name.
+func Registered() []Registration {
+	var rs []Registration
+	for _, r := range registry {
+		rs = append(rs, r)
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+	return rs
+}
+
+// Resolve returns the named generators along with the generators they require,
+// every generator goes after its requirements.
+func Resolve(names ...string) ([]Registration, error) {
+	var rs []Registration
+	state := map[string]int{} // 1 visiting, 2 done
+	var visit func(name, from string) error
+	visit = func(name, from string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("generators: %s requires itself through %s", name, from)
+		case 2:
+			return nil
+		}
+		r, ok := registry[name]
+		if !ok {
+			if from != "" {
+				return fmt.Errorf("generators: %s requires unknown generator %s", from, name)
+			}
+			return fmt.Errorf("generators: unknown generator %s", name)
+		}
+		state[name] = 1
+		for _, req := range r.Requires {
+			if err := visit(req, name); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		rs = append(rs, r)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, ""); err != nil {
+			return nil, err
+		}
+	}
+	return rs, nil
+}
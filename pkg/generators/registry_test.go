@@ -0,0 +1,74 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+)
+
+// registerTest registers generators with the names and requirements until the test ends.
+func registerTest(t *testing.T, requires map[string][]string) {
+	for name, reqs := range requires {
+		Register(Registration{Name: name, Requires: reqs})
+	}
+	t.Cleanup(func() {
+		for name := range requires {
+			delete(registry, name)
+		}
+	})
+}
+
+func TestResolve(t *testing.T) {
+	registerTest(t, map[string][]string{
+		"test-a":       {"test-b", "test-c"},
+		"test-b":       {"test-c"},
+		"test-c":       nil,
+		"test-self":    {"test-self"},
+		"test-cycle1":  {"test-cycle2"},
+		"test-cycle2":  {"test-cycle1"},
+		"test-unknown": {"test-c", "test-missing"},
+	})
+	cases := []struct {
+		names []string
+		want  []string
+		err   string
+	}{
+		{names: []string{"http-test"}, want: []string{"endpoint", "http", "http-test"}},
+		{names: []string{"endpoint", "http"}, want: []string{"endpoint", "http"}},
+		{names: []string{"http", "endpoint", "http"}, want: []string{"endpoint", "http"}},
+		{names: []string{"test-a"}, want: []string{"test-c", "test-b", "test-a"}},
+		{names: []string{"test-c", "test-a"}, want: []string{"test-c", "test-b", "test-a"}},
+		{names: []string{"test-self"}, err: "generators: test-self requires itself through test-self"},
+		{names: []string{"test-cycle1"}, err: "generators: test-cycle1 requires itself through test-cycle2"},
+		{names: []string{"test-unknown"}, err: "generators: test-unknown requires unknown generator test-missing"},
+		{names: []string{"test-c", "test-missing"}, err: "generators: unknown generator test-missing"},
+	}
+	for _, tc := range cases {
+		rs, err := Resolve(tc.names...)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("Resolve(%q): got error %v, want %q", tc.names, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", tc.names, err)
+			continue
+		}
+		var got []string
+		for _, r := range rs {
+			got = append(got, r.Name)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Resolve(%q): got %q, want %q", tc.names, got, tc.want)
+		}
+	}
+}
+
+func TestRegisterTaken(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register: no panic for the taken name")
+		}
+	}()
+	Register(Registration{Name: "endpoint"})
+}
@@ -0,0 +1,178 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+)
+
+// Resilience resilience middlewares of a client endpoint, durations are Go expressions.
+type Resilience struct {
+	// Timeout timeout of an endpoint call, empty without a timeout.
+	Timeout string
+	// Breaker circuit breaker, nil without a breaker.
+	Breaker *ResilienceBreaker
+	// RateLimit token bucket rate limit, nil without a limit.
+	RateLimit *ResilienceRateLimit
+	// Retry retries of the failed calls, nil without retries.
+	Retry *ResilienceRetry
+}
+
+// ResilienceBreaker circuit breaker settings, see config.Breaker.
+type ResilienceBreaker struct {
+	Failures    int
+	Timeout     string
+	MaxRequests int
+}
+
+// ResilienceRateLimit rate limit settings, see config.RateLimit.
+type ResilienceRateLimit struct {
+	Rate  string
+	Burst int
+}
+
+// ResilienceRetry retry settings, see config.Retry.
+type ResilienceRetry struct {
+	Attempts int
+	Backoff  string
+	Timeout  string
+}
+
+// Middleware reports whether every call is wrapped with a breaker, a rate limit or a timeout.
+func (r Resilience) Middleware() bool {
+	return r.Breaker != nil || r.RateLimit != nil || r.Timeout != ""
+}
+
+// Resilient reports whether any endpoint has resilience middlewares.
+func (d Data) Resilient() bool {
+	for _, e := range d.Endpoints {
+		if e.Resilience != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// durationExpr returns a Go expression of the duration, e.g. "2 * time.Second".
+func durationExpr(s string) (string, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return "", err
+	}
+	if d <= 0 {
+		return "", fmt.Errorf("duration %s is not positive", s)
+	}
+	for _, u := range []struct {
+		d    time.Duration
+		name string
+	}{{time.Hour, "time.Hour"}, {time.Minute, "time.Minute"}, {time.Second, "time.Second"}, {time.Millisecond, "time.Millisecond"}, {time.Microsecond, "time.Microsecond"}} {
+		if d%u.d == 0 {
+			return fmt.Sprintf("%d * %s", d/u.d, u.name), nil
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", d), nil
+}
+
+// newResilience returns the middlewares of the method settings merged over the defaults, nil if there are none.
+func newResilience(defaults, method config.EndpointResilience) (*Resilience, error) {
+	if method.Timeout != "" {
+		defaults.Timeout = method.Timeout
+	}
+	if method.Breaker != nil {
+		defaults.Breaker = method.Breaker
+	}
+	if method.RateLimit != nil {
+		defaults.RateLimit = method.RateLimit
+	}
+	if method.Retry != nil {
+		defaults.Retry = method.Retry
+	}
+	c := defaults
+	if c.Timeout == "" && c.Breaker == nil && c.RateLimit == nil && c.Retry == nil {
+		return nil, nil
+	}
+
+	r := &Resilience{}
+	var err error
+	if c.Timeout != "" {
+		if r.Timeout, err = durationExpr(c.Timeout); err != nil {
+			return nil, fmt.Errorf("timeout: %v", err)
+		}
+	}
+	if b := c.Breaker; b != nil {
+		r.Breaker = &ResilienceBreaker{Failures: 5, Timeout: "60 * time.Second", MaxRequests: 1}
+		if b.Failures < 0 || b.MaxRequests < 0 {
+			return nil, fmt.Errorf("breaker: failures and max requests must not be negative")
+		}
+		if b.Failures > 0 {
+			r.Breaker.Failures = b.Failures
+		}
+		if b.MaxRequests > 0 {
+			r.Breaker.MaxRequests = b.MaxRequests
+		}
+		if b.Timeout != "" {
+			if r.Breaker.Timeout, err = durationExpr(b.Timeout); err != nil {
+				return nil, fmt.Errorf("breaker timeout: %v", err)
+			}
+		}
+	}
+	if l := c.RateLimit; l != nil {
+		if l.Rate <= 0 || l.Burst < 0 {
+			return nil, fmt.Errorf("rate limit: rate must be positive and burst must not be negative")
+		}
+		r.RateLimit = &ResilienceRateLimit{Rate: strconv.FormatFloat(l.Rate, 'g', -1, 64), Burst: l.Burst}
+		if l.Burst == 0 {
+			r.RateLimit.Burst = int(math.Ceil(l.Rate))
+		}
+	}
+	if rt := c.Retry; rt != nil {
+		r.Retry = &ResilienceRetry{Attempts: 3, Backoff: "100 * time.Millisecond", Timeout: "10 * time.Second"}
+		if rt.Attempts < 0 {
+			return nil, fmt.Errorf("retry: attempts must not be negative")
+		}
+		if rt.Attempts > 0 {
+			r.Retry.Attempts = rt.Attempts
+		}
+		if rt.Backoff != "" {
+			if r.Retry.Backoff, err = durationExpr(rt.Backoff); err != nil {
+				return nil, fmt.Errorf("retry backoff: %v", err)
+			}
+		}
+		if rt.Timeout != "" {
+			if r.Retry.Timeout, err = durationExpr(rt.Timeout); err != nil {
+				return nil, fmt.Errorf("retry timeout: %v", err)
+			}
+		}
+	}
+	return r, nil
+}
+
+// resilience sets resilience middlewares of the endpoints by the config.
+func resilience(endpoints []Endpoint, cfg config.Resilience) error {
+	methods := map[string]bool{}
+	for i := range endpoints {
+		e := &endpoints[i]
+		methods[e.Method.Name] = true
+		r, err := newResilience(cfg.Defaults, cfg.Methods[e.Method.Name])
+		if err != nil {
+			return fmt.Errorf("resilience: %s: %v", e.Method.Name, err)
+		}
+		e.Resilience = r
+	}
+	var unknown []string
+	for name := range cfg.Methods {
+		if !methods[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("resilience: unknown methods %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package generators
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+)
+
+func TestDurationExpr(t *testing.T) {
+	cases := []struct {
+		in, want, err string
+	}{
+		{"2h", "2 * time.Hour", ""},
+		{"90m", "90 * time.Minute", ""},
+		{"1m30s", "90 * time.Second", ""},
+		{"1.5s", "1500 * time.Millisecond", ""},
+		{"250us", "250 * time.Microsecond", ""},
+		{"10ns", "time.Duration(10)", ""},
+		{"0s", "", "is not positive"},
+		{"-1s", "", "is not positive"},
+		{"10", "", "missing unit"},
+	}
+	for _, tc := range cases {
+		got, err := durationExpr(tc.in)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("durationExpr(%q): got error %v, want %q", tc.in, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("durationExpr(%q): got %q, %v, want %q", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestNewResilience(t *testing.T) {
+	cases := []struct {
+		name             string
+		defaults, method config.EndpointResilience
+		want             *Resilience
+		err              string
+	}{
+		{name: "none"},
+		{
+			name:     "defaults",
+			defaults: config.EndpointResilience{Timeout: "2s", Breaker: &config.Breaker{}, RateLimit: &config.RateLimit{Rate: 2.5}, Retry: &config.Retry{}},
+			want: &Resilience{
+				Timeout:   "2 * time.Second",
+				Breaker:   &ResilienceBreaker{Failures: 5, Timeout: "60 * time.Second", MaxRequests: 1},
+				RateLimit: &ResilienceRateLimit{Rate: "2.5", Burst: 3},
+				Retry:     &ResilienceRetry{Attempts: 3, Backoff: "100 * time.Millisecond", Timeout: "10 * time.Second"},
+			},
+		},
+		{
+			name:     "method replaces the defaults",
+			defaults: config.EndpointResilience{Timeout: "2s", Retry: &config.Retry{Attempts: 5}},
+			method: config.EndpointResilience{
+				Breaker: &config.Breaker{Failures: 3, Timeout: "30s", MaxRequests: 2},
+				Retry:   &config.Retry{Attempts: 2, Backoff: "1s", Timeout: "1m"},
+			},
+			want: &Resilience{
+				Timeout: "2 * time.Second",
+				Breaker: &ResilienceBreaker{Failures: 3, Timeout: "30 * time.Second", MaxRequests: 2},
+				Retry:   &ResilienceRetry{Attempts: 2, Backoff: "1 * time.Second", Timeout: "1 * time.Minute"},
+			},
+		},
+		{
+			name:   "explicit burst",
+			method: config.EndpointResilience{RateLimit: &config.RateLimit{Rate: 10, Burst: 1}},
+			want:   &Resilience{RateLimit: &ResilienceRateLimit{Rate: "10", Burst: 1}},
+		},
+		{name: "invalid timeout", method: config.EndpointResilience{Timeout: "soon"}, err: "timeout:"},
+		{name: "negative failures", method: config.EndpointResilience{Breaker: &config.Breaker{Failures: -1}}, err: "breaker:"},
+		{name: "invalid breaker timeout", method: config.EndpointResilience{Breaker: &config.Breaker{Timeout: "0s"}}, err: "breaker timeout:"},
+		{name: "zero rate", method: config.EndpointResilience{RateLimit: &config.RateLimit{}}, err: "rate limit:"},
+		{name: "negative attempts", method: config.EndpointResilience{Retry: &config.Retry{Attempts: -1}}, err: "retry:"},
+		{name: "invalid backoff", method: config.EndpointResilience{Retry: &config.Retry{Backoff: "1"}}, err: "retry backoff:"},
+		{name: "invalid retry timeout", method: config.EndpointResilience{Retry: &config.Retry{Timeout: "-1s"}}, err: "retry timeout:"},
+	}
+	for _, tc := range cases {
+		got, err := newResilience(tc.defaults, tc.method)
+		if tc.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+				t.Errorf("%s: got error %v, want %q", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, %v, want %+v", tc.name, got, err, tc.want)
+		}
+	}
+}
+
+func TestResilienceMethods(t *testing.T) {
+	endpoints := newData(testResult(), nil).Endpoints
+	cfg := config.Resilience{Methods: map[string]config.EndpointResilience{"Get": {Timeout: "1s"}}}
+	if err := resilience(endpoints, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if endpoints[0].Resilience != nil {
+		t.Errorf("Say: got %+v, want no middlewares", endpoints[0].Resilience)
+	}
+	if r := endpoints[1].Resilience; r == nil || !r.Middleware() || r.Timeout != "1 * time.Second" {
+		t.Errorf("Get: got %+v, want a timeout", r)
+	}
+	if !(Data{Endpoints: endpoints}).Resilient() {
+		t.Error("Resilient: got false")
+	}
+
+	cfg.Methods["Nope"] = config.EndpointResilience{}
+	if err := resilience(endpoints, cfg); err == nil || !strings.Contains(err.Error(), "unknown methods Nope") {
+		t.Errorf("got error %v, want unknown methods", err)
+	}
+	cfg = config.Resilience{Methods: map[string]config.EndpointResilience{"Say": {Timeout: "x"}}}
+	if err := resilience(endpoints, cfg); err == nil || !strings.HasPrefix(err.Error(), "resilience: Say: timeout:") {
+		t.Errorf("got error %v, want the method in it", err)
+	}
+}
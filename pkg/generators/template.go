@@ -0,0 +1,314 @@
+package generators
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+	"github.com/l-vitaly/gokitgen/pkg/imports"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+	"github.com/l-vitaly/gokitgen/pkg/utils"
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+var templateFuncs = template.FuncMap{
+	"lcFirst": utils.LcFirst,
+	"ucFirst": utils.UcFirst,
+	"kebab":   utils.KebabCase,
+	"join":    strings.Join,
+	"dict":    dict,
+	"status":  httpStatus,
+	"typeArgs": func(params []parser.Field) string {
+		if len(params) == 0 {
+			return ""
+		}
+		var names []string
+		for _, p := range params {
+			names = append(names, p.Name)
+		}
+		return "[" + strings.Join(names, ", ") + "]"
+	},
+	"sample": sample,
+	"parseParam": func(f parser.Field, value string) string {
+		return fmt.Sprintf(stringConverters[f.Type].Parse, value)
+	},
+	"convertParam": func(f parser.Field, value string) string {
+		if conv := stringConverters[f.Type]; conv.Result != f.Type {
+			return f.Type + "(" + value + ")"
+		}
+		return value
+	},
+	"formatParam": func(f parser.Field, value string) string {
+		return fmt.Sprintf(stringConverters[f.Type].Format, value)
+	},
+}
+
+// importsMarker is replaced with the imports block of the rendered Go file.
+const importsMarker = "// gokitgen:imports\n"
+
+// importFuncs returns the template functions managing imports of the file:
+// import declares a package the template code refers to, imports places the
+// imports block, typeOf and paramType return field types and typeParams the
+// type parameter list of a generic service with their package names,
+// the packages are imported only if the code refers to them.
+func importFuncs(m *imports.Manager) template.FuncMap {
+	return template.FuncMap{
+		"import": func(path string, name ...string) (string, error) {
+			if len(name) > 1 {
+				return "", fmt.Errorf("import %q: too many names", path)
+			}
+			return "", m.Declare(path, strings.Join(name, ""))
+		},
+		"imports": func() string {
+			return importsMarker
+		},
+		"typeOf": func(f parser.Field) (string, error) {
+			return qualify(m, f)
+		},
+		"paramType": func(f parser.Field) (string, error) {
+			t, err := qualify(m, f)
+			if f.Variadic {
+				t = "..." + strings.TrimPrefix(t, "[]")
+			}
+			return t, err
+		},
+		"typeParams": func(params []parser.Field) (string, error) {
+			if len(params) == 0 {
+				return "", nil
+			}
+			var list []string
+			for _, p := range params {
+				t, err := qualify(m, p)
+				if err != nil {
+					return "", err
+				}
+				list = append(list, p.Name+" "+t)
+			}
+			return "[" + strings.Join(list, ", ") + "]", nil
+		},
+	}
+}
+
+// qualify returns the field type with package names assigned by m.
+func qualify(m *imports.Manager, f parser.Field) (string, error) {
+	if len(f.Imports) == 0 {
+		return f.Type, nil
+	}
+	expr, err := goparser.ParseExpr(f.Type)
+	if err != nil {
+		return "", fmt.Errorf("field %s: %v", f.Name, err)
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				if path, ok := f.Imports[id.Name]; ok {
+					id.Name = m.Ref(path, id.Name)
+				}
+			}
+		}
+		return true
+	})
+	return types.ExprString(expr), nil
+}
+
+// dict builds a map from key and value pairs, so templates can pass several values to a {{template}}.
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// Templates returns names of the embedded templates.
+func Templates() []string {
+	entries, _ := templatesFS.ReadDir("templates")
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// loadTemplate parses the named template. Built-in templates are parsed first
+// and then the file with the same name from dir, if any, so it may replace
+// either the whole template or only some of its {{define}} blocks.
+func loadTemplate(name, dir string, m *imports.Manager) (*template.Template, error) {
+	t := template.New(name).Funcs(templateFuncs).Funcs(importFuncs(m))
+
+	found := false
+	if data, err := templatesFS.ReadFile("templates/" + name); err == nil {
+		if _, err := t.Parse(string(data)); err != nil {
+			return nil, err
+		}
+		found = true
+	}
+
+	if dir != "" {
+		filename := filepath.Join(dir, name)
+		data, err := ioutil.ReadFile(filename)
+		switch {
+		case err == nil:
+			if _, err := t.Parse(string(data)); err != nil {
+				return nil, fmt.Errorf("%s: %v", filename, err)
+			}
+			found = true
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+	return t, nil
+}
+
+// renderTemplate executes the named template, Go sources get their imports and are formatted.
+// The template is executed twice, the first pass collects packages of the service types,
+// so they are named without conflicts in the second one.
+func renderTemplate(name, dir string, data interface{}) ([]byte, error) {
+	m := imports.NewManager()
+	t, err := loadTemplate(name, dir, m)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Execute(ioutil.Discard, data); err != nil {
+		return nil, err
+	}
+	m.Resolve()
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(name, ".go.tmpl") {
+		return buf.Bytes(), nil
+	}
+	src := buf.Bytes()
+	if bytes.Contains(src, []byte(importsMarker)) {
+		used, err := m.Used(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		src = bytes.Replace(src, []byte(importsMarker), []byte(imports.Block(used)), 1)
+	}
+	src, err = format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return src, nil
+}
+
+// TemplateGeneratorOption template generator option.
+type TemplateGeneratorOption func(g *templateGenerator)
+
+// TemplateGeneratorDir directory the template is looked up in before the built-in templates.
+func TemplateGeneratorDir(dir string) TemplateGeneratorOption {
+	return func(g *templateGenerator) {
+		g.dir = dir
+	}
+}
+
+// TemplateGeneratorOptions options available to the template as .Options.
+func TemplateGeneratorOptions(options map[string]interface{}) TemplateGeneratorOption {
+	return func(g *templateGenerator) {
+		g.options = options
+	}
+}
+
+// TemplateGeneratorOutput generated file name, the template name without the .tmpl extension by default.
+func TemplateGeneratorOutput(output string) TemplateGeneratorOption {
+	return func(g *templateGenerator) {
+		g.output = output
+	}
+}
+
+// TemplateGeneratorHTTPConfig routes and error codes available to the template as .HTTP.
+func TemplateGeneratorHTTPConfig(cfg config.HTTPTransport) TemplateGeneratorOption {
+	return func(g *templateGenerator) {
+		g.httpCfg = cfg
+	}
+}
+
+type templateGenerator struct {
+	name    string
+	output  string
+	dir     string
+	options map[string]interface{}
+	httpCfg config.HTTPTransport
+}
+
+func (g *templateGenerator) Generate(result parser.Result) ([]File, error) {
+	data := newData(result, g.options)
+	routes, err := newHTTPRoutes(data.Endpoints, g.httpCfg)
+	if err != nil {
+		return nil, err
+	}
+	data.HTTP = HTTPData{
+		Routes: routes,
+		Errors: httpErrors(g.httpCfg, result.Prefix),
+	}
+	src, err := renderTemplate(g.name, g.dir, data)
+	if err != nil {
+		return nil, err
+	}
+	return []File{{Name: g.output, Data: src}}, nil
+}
+
+func init() {
+	Register(Registration{
+		Name:      "template",
+		Command:   []string{"template"},
+		Usage:     "runs a template generator declared in the config",
+		ArgsUsage: "<name>",
+		New: func(o Options) (Generator, error) {
+			if len(o.Args) == 0 {
+				return nil, fmt.Errorf("template generator name is required")
+			}
+			name := o.Args[0]
+			tg, ok := o.Config.Templates.Generators[name]
+			if !ok {
+				return nil, fmt.Errorf("template generator %q is not declared in the config", name)
+			}
+			if tg.Template == "" || tg.Output == "" {
+				return nil, fmt.Errorf("template generator %q: template and output are required", name)
+			}
+			return NewTemplate(
+				tg.Template,
+				TemplateGeneratorOutput(tg.Output),
+				TemplateGeneratorDir(o.TemplateDir),
+				TemplateGeneratorOptions(tg.Options),
+				TemplateGeneratorHTTPConfig(o.HTTP),
+			), nil
+		},
+	})
+}
+
+// NewTemplate creates a generator executing the named template with Data.
+func NewTemplate(name string, options ...TemplateGeneratorOption) Generator {
+	g := &templateGenerator{name: name, output: strings.TrimSuffix(name, ".tmpl")}
+	for _, o := range options {
+		o(g)
+	}
+	return g
+}
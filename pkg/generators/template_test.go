@@ -0,0 +1,113 @@
+package generators
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+)
+
+func TestRenderTemplateOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"custom.txt.tmpl":   "{{.Pkg}}:{{range .Endpoints}} {{.Method.Name}}{{end}}\n",
+		"logging.go.tmpl":   "package {{.Pkg}}\n\n// Logging replaced.\n",
+		"endpoints.go.tmpl": "{{define \"failure\"}}// Failure replaced.\n{{end}}",
+		"bad.txt.tmpl":      "{{.Pkg",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name    string
+		dir     string
+		want    []string
+		wantNot []string
+		err     string
+	}{
+		{name: "endpoints.go.tmpl", want: []string{"func Failure(", "type set struct"}},
+		{name: "custom.txt.tmpl", dir: dir, want: []string{"hello: Say Get\n"}},
+		{name: "logging.go.tmpl", dir: dir, want: []string{"// Logging replaced."}, wantNot: []string{"func (mw"}},
+		{name: "endpoints.go.tmpl", dir: dir, want: []string{"// Failure replaced.", "type set struct"}, wantNot: []string{"func Failure("}},
+		{name: "custom.txt.tmpl", err: "template custom.txt.tmpl not found"},
+		{name: "missing.tmpl", dir: dir, err: "template missing.tmpl not found"},
+		{name: "bad.txt.tmpl", dir: dir, err: filepath.Join(dir, "bad.txt.tmpl") + ":"},
+	}
+	for _, tc := range cases {
+		src, err := renderTemplate(tc.name, tc.dir, newData(testResult(), nil))
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s in %q: got error %v, want %q", tc.name, tc.dir, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s in %q: %v", tc.name, tc.dir, err)
+			continue
+		}
+		for _, s := range tc.want {
+			if !strings.Contains(string(src), s) {
+				t.Errorf("%s in %q: %q not found in\n%s", tc.name, tc.dir, s, src)
+			}
+		}
+		for _, s := range tc.wantNot {
+			if strings.Contains(string(src), s) {
+				t.Errorf("%s in %q: %q found in\n%s", tc.name, tc.dir, s, src)
+			}
+		}
+	}
+}
+
+func TestTemplateGenerator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tmpl := "{{.Pkg}} {{.Options.greeting}}{{range .HTTP.Routes}} {{.Method}} {{.Path}}{{end}}\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "routes.txt.tmpl"), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{Templates: config.Templates{Generators: map[string]config.TemplateGenerator{
+		"routes":    {Template: "routes.txt.tmpl", Output: "routes.txt", Options: map[string]interface{}{"greeting": "hi"}},
+		"no-output": {Template: "routes.txt.tmpl"},
+	}}}
+	cases := []struct {
+		args []string
+		want string
+		err  string
+	}{
+		{args: []string{"routes"}, want: "hello hi GET /say/{name} POST /items/{id}\n"},
+		{err: "template generator name is required"},
+		{args: []string{"unknown"}, err: `template generator "unknown" is not declared in the config`},
+		{args: []string{"no-output"}, err: `template generator "no-output": template and output are required`},
+	}
+	for _, tc := range cases {
+		files, err := generate("template", Options{Args: tc.args, TemplateDir: dir, HTTP: testHTTPConfig(""), Config: cfg})
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("template %q: got error %v, want %q", tc.args, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("template %q: %v", tc.args, err)
+			continue
+		}
+		if got := files["routes.txt"]; got != tc.want {
+			t.Errorf("template %q: got %q, want %q", tc.args, got, tc.want)
+		}
+	}
+}
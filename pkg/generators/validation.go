@@ -0,0 +1,373 @@
+package generators
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/l-vitaly/gokitgen/pkg/naming"
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+)
+
+// ValidateDirective method annotation declaring validation rules of a param,
+// e.g. //gokit:validate name required maxlen=64 pattern=^[a-z]+$.
+const ValidateDirective = "//gokit:validate"
+
+// ValidationCheck check of a request field.
+type ValidationCheck struct {
+	// Cond Go expression of the request r, it is true if the field is invalid.
+	Cond string
+	// Message error message of the invalid field.
+	Message string
+}
+
+// FieldValidation validation of a request field, checks run in order until one fails.
+type FieldValidation struct {
+	Field  EndpointTransportDataField
+	Checks []ValidationCheck
+	// Pattern regexp the field must match, it is compiled to the PatternVar package variable.
+	Pattern    string
+	PatternVar string
+	// Sample literal of a valid value used by tests, empty for the zero value.
+	Sample string
+	// Sampled reports whether Sample passes the checks.
+	Sampled bool
+}
+
+// validationRule validation rules of a param.
+type validationRule struct {
+	required       bool
+	min, max       string
+	minLen, maxLen int
+	pattern        string
+	enum           []string
+}
+
+// parseValidationRule parses space separated rules: required, min=N, max=N, minlen=N, maxlen=N,
+// pattern=REGEXP and enum=A|B|C.
+func parseValidationRule(s string) (validationRule, error) {
+	r := validationRule{minLen: -1, maxLen: -1}
+	for _, f := range strings.Fields(s) {
+		if f == "required" {
+			r.required = true
+			continue
+		}
+		i := strings.Index(f, "=")
+		if i <= 0 {
+			return r, fmt.Errorf("unknown rule %q", f)
+		}
+		key, value := f[:i], f[i+1:]
+		switch key {
+		case "min", "max":
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return r, fmt.Errorf("%s %q is not a number", key, value)
+			}
+			if key == "min" {
+				r.min = value
+			} else {
+				r.max = value
+			}
+		case "minlen", "maxlen":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return r, fmt.Errorf("%s %q is not a length", key, value)
+			}
+			if key == "minlen" {
+				r.minLen = n
+			} else {
+				r.maxLen = n
+			}
+		case "pattern":
+			if _, err := regexp.Compile(value); err != nil {
+				return r, fmt.Errorf("pattern: %v", err)
+			}
+			r.pattern = value
+		case "enum":
+			r.enum = strings.Split(value, "|")
+		default:
+			return r, fmt.Errorf("unknown rule %q", key)
+		}
+	}
+	return r, nil
+}
+
+// validationKind returns the kind of values of the type rules apply to, an empty string if rules are not supported.
+func validationKind(typ string) string {
+	switch typ {
+	case "string":
+		return "string"
+	case "bool":
+		return "bool"
+	case "float32", "float64":
+		return "float"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return "int"
+	}
+	switch {
+	case strings.HasPrefix(typ, "[]"), strings.HasPrefix(typ, "map["):
+		return "len"
+	case strings.HasPrefix(typ, "*"), strings.HasPrefix(typ, "chan "), strings.HasPrefix(typ, "func("),
+		typ == "interface{}", typ == "any":
+		return "nil"
+	}
+	return ""
+}
+
+// validationRules returns the rules of the method params by param name, config rules replace the annotations.
+func validationRules(m parser.Method, cfg map[string]string) (map[string]string, error) {
+	rules := map[string]string{}
+	for _, d := range m.Directives {
+		if d != ValidateDirective && !strings.HasPrefix(d, ValidateDirective+" ") {
+			continue
+		}
+		fields := strings.Fields(d[len(ValidateDirective):])
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s: param name is required", ValidateDirective)
+		}
+		rules[fields[0]] = strings.Join(fields[1:], " ")
+	}
+	for name, rule := range cfg {
+		rules[name] = rule
+	}
+	return rules, nil
+}
+
+// validations sets validations of the request fields of the endpoints by the rules of the config,
+// rules by service method and param name, and of the //gokit:validate annotations.
+func validations(endpoints []Endpoint, cfg map[string]map[string]string) error {
+	methods := map[string]bool{}
+	for i := range endpoints {
+		e := &endpoints[i]
+		methods[e.Method.Name] = true
+		rules, err := validationRules(e.Method, cfg[e.Method.Name])
+		if err != nil {
+			return fmt.Errorf("validate: %s: %v", e.Method.Name, err)
+		}
+		e.Validation = nil
+		for j, f := range e.Params() {
+			s, ok := rules[f.Field.DeclaredName()]
+			if !ok {
+				continue
+			}
+			delete(rules, f.Field.DeclaredName())
+			rule, err := parseValidationRule(s)
+			if err != nil {
+				return fmt.Errorf("validate: %s %s: %v", e.Method.Name, f.Field.DeclaredName(), err)
+			}
+			v, err := fieldValidation(*e, f, rule, j)
+			if err != nil {
+				return fmt.Errorf("validate: %s %s: %v", e.Method.Name, f.Field.DeclaredName(), err)
+			}
+			if len(v.Checks) > 0 {
+				e.Validation = append(e.Validation, v)
+			}
+		}
+		if len(rules) > 0 {
+			var unknown []string
+			for name := range rules {
+				unknown = append(unknown, name)
+			}
+			sort.Strings(unknown)
+			return fmt.Errorf("validate: %s has no params %s", e.Method.Name, strings.Join(unknown, ", "))
+		}
+	}
+	var unknown []string
+	for name := range cfg {
+		if !methods[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("validate: unknown methods %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// fieldValidation returns checks of the request field by the rule, i is the index of the param.
+func fieldValidation(e Endpoint, f EndpointTransportDataField, rule validationRule, i int) (FieldValidation, error) {
+	v := FieldValidation{Field: f}
+	kind := validationKind(f.Field.Type)
+	if kind == "" {
+		return v, fmt.Errorf("type %s is not supported", f.Field.Type)
+	}
+	x := "r." + f.Name
+	check := func(cond, format string, args ...interface{}) {
+		v.Checks = append(v.Checks, ValidationCheck{Cond: cond, Message: fmt.Sprintf(format, args...)})
+	}
+	unsupported := func(name string) error {
+		return fmt.Errorf("type %s does not support %s", f.Field.Type, name)
+	}
+
+	empty := map[string]string{"string": x + ` == ""`, "int": x + " == 0", "float": x + " == 0", "bool": "!" + x, "len": "len(" + x + ") == 0", "nil": x + " == nil"}[kind]
+	if rule.required {
+		check(empty, "is required")
+	}
+	// optional strings, slices and maps are checked only if they are not empty
+	optional := func(cond string) string {
+		if !rule.required && (kind == "string" || kind == "len") {
+			return "len(" + x + ") > 0 && " + cond
+		}
+		return cond
+	}
+
+	if rule.min != "" || rule.max != "" {
+		if kind != "int" && kind != "float" {
+			return v, unsupported("min and max")
+		}
+		for _, b := range []struct{ value, op, msg string }{{rule.min, "<", "must be at least %s"}, {rule.max, ">", "must be at most %s"}} {
+			if b.value == "" {
+				continue
+			}
+			if _, err := strconv.ParseInt(b.value, 10, 64); err != nil && kind == "int" {
+				return v, fmt.Errorf("%s is not an integer", b.value)
+			}
+			if strings.HasPrefix(b.value, "-") && strings.HasPrefix(f.Field.Type, "uint") {
+				return v, fmt.Errorf("%s is negative", b.value)
+			}
+			check(x+" "+b.op+" "+b.value, b.msg, b.value)
+		}
+	}
+	if rule.minLen >= 0 || rule.maxLen >= 0 {
+		length := "len(" + x + ")"
+		switch kind {
+		case "string":
+			length = "utf8.RuneCountInString(" + x + ")"
+		case "len":
+		default:
+			return v, unsupported("minlen and maxlen")
+		}
+		if rule.minLen >= 0 {
+			check(optional(fmt.Sprintf("%s < %d", length, rule.minLen)), "length must be at least %d", rule.minLen)
+		}
+		if rule.maxLen >= 0 {
+			check(fmt.Sprintf("%s > %d", length, rule.maxLen), "length must be at most %d", rule.maxLen)
+		}
+	}
+	if rule.pattern != "" {
+		if kind != "string" {
+			return v, unsupported("pattern")
+		}
+		v.Pattern = rule.pattern
+		v.PatternVar = e.Ident(naming.Unexported(e.Method.Name) + naming.Exported(f.Name) + "Pattern")
+		check(optional("!"+v.PatternVar+".MatchString("+x+")"), "must match %s", rule.pattern)
+	}
+	if len(rule.enum) > 0 {
+		var conds, values []string
+		for _, value := range rule.enum {
+			lit := value
+			switch kind {
+			case "string":
+				lit = strconv.Quote(value)
+			case "int":
+				if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+					return v, fmt.Errorf("enum value %q is not an integer", value)
+				}
+			case "float":
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return v, fmt.Errorf("enum value %q is not a number", value)
+				}
+			default:
+				return v, unsupported("enum")
+			}
+			conds = append(conds, x+" != "+lit)
+			values = append(values, value)
+		}
+		check(optional(strings.Join(conds, " && ")), "must be one of %s", strings.Join(values, ", "))
+	}
+
+	v.Sample, v.Sampled = validSample(f.Field, kind, rule, i)
+	return v, nil
+}
+
+// validSample returns a literal of the i-th param passing the rule and whether it is found,
+// see sample.
+func validSample(f parser.Field, kind string, rule validationRule, i int) (string, bool) {
+	switch kind {
+	case "string":
+		s := f.Name
+		if len(rule.enum) > 0 {
+			s = rule.enum[0]
+		}
+		if n := utf8.RuneCountInString(s); rule.minLen > n {
+			s += strings.Repeat("x", rule.minLen-n)
+		}
+		if rule.maxLen >= 0 && utf8.RuneCountInString(s) > rule.maxLen {
+			s = string([]rune(s)[:rule.maxLen])
+		}
+		ok := !rule.required || s != ""
+		if rule.pattern != "" && s != "" {
+			ok = ok && regexp.MustCompile(rule.pattern).MatchString(s)
+		}
+		return strconv.Quote(s), ok
+	case "int", "float":
+		n := float64(i + 1)
+		if kind == "float" {
+			n += 0.5
+		}
+		if len(rule.enum) > 0 {
+			n, _ = strconv.ParseFloat(rule.enum[0], 64)
+		}
+		if rule.min != "" {
+			if min, _ := strconv.ParseFloat(rule.min, 64); n < min {
+				n = min
+			}
+		}
+		ok := true
+		if rule.max != "" {
+			if max, _ := strconv.ParseFloat(rule.max, 64); n > max {
+				n = max
+				if rule.min != "" {
+					min, _ := strconv.ParseFloat(rule.min, 64)
+					ok = n >= min
+				}
+			}
+		}
+		ok = ok && (!rule.required || n != 0)
+		if kind == "int" && n != float64(int64(n)) {
+			return "", false
+		}
+		if strings.HasPrefix(f.Type, "uint") && n < 0 {
+			return "", false
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), ok
+	case "bool":
+		return "true", true
+	}
+	// slices, maps and pointers are sampled by their zero value
+	return "", !rule.required && rule.minLen <= 0
+}
+
+// Validated reports whether requests of any endpoint are validated.
+func (d Data) Validated() bool {
+	for _, e := range d.Endpoints {
+		if len(e.Validation) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Sample returns a literal of the i-th param for tests, it passes the validation of the param if it has any.
+func (e Endpoint) Sample(f EndpointTransportDataField, i int) string {
+	for _, v := range e.Validation {
+		if v.Field.Name == f.Name {
+			return v.Sample
+		}
+	}
+	return sample(f.Field, i)
+}
+
+// Unsampled returns names of the params without samples passing their validation.
+func (e Endpoint) Unsampled() []string {
+	var names []string
+	for _, v := range e.Validation {
+		if !v.Sampled {
+			names = append(names, v.Field.Field.DeclaredName())
+		}
+	}
+	return names
+}
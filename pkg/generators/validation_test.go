@@ -0,0 +1,185 @@
+package generators
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+)
+
+func TestParseValidationRule(t *testing.T) {
+	cases := []struct {
+		in   string
+		want validationRule
+		err  string
+	}{
+		{"", validationRule{minLen: -1, maxLen: -1}, ""},
+		{"required min=1 max=2.5", validationRule{required: true, min: "1", max: "2.5", minLen: -1, maxLen: -1}, ""},
+		{"minlen=0 maxlen=64", validationRule{minLen: 0, maxLen: 64}, ""},
+		{"pattern=^[a-z]+$ enum=a|b", validationRule{minLen: -1, maxLen: -1, pattern: "^[a-z]+$", enum: []string{"a", "b"}}, ""},
+		{"optional", validationRule{}, `unknown rule "optional"`},
+		{"=1", validationRule{}, `unknown rule "=1"`},
+		{"size=1", validationRule{}, `unknown rule "size"`},
+		{"min=x", validationRule{}, `min "x" is not a number`},
+		{"maxlen=-1", validationRule{}, `maxlen "-1" is not a length`},
+		{"pattern=(", validationRule{}, "pattern: error parsing regexp"},
+	}
+	for _, tc := range cases {
+		got, err := parseValidationRule(tc.in)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("parseValidationRule(%q): got error %v, want %q", tc.in, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseValidationRule(%q): got %+v, %v, want %+v", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func TestValidationRules(t *testing.T) {
+	m := parser.Method{Directives: []string{
+		"//gokit:validate name required",
+		"//gokit:validated id",
+		"//gokit:validate id min=1",
+	}}
+	got, err := validationRules(m, map[string]string{"name": "maxlen=3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"name": "maxlen=3", "id": "min=1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	m.Directives = []string{"//gokit:validate"}
+	if _, err := validationRules(m, nil); err == nil {
+		t.Error("no error for the annotation without a param name")
+	}
+}
+
+func TestValidations(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  map[string]map[string]string
+		// checks conditions of the checks by Method.param
+		checks map[string][]string
+		err    string
+	}{
+		{name: "none"},
+		{
+			name: "string",
+			cfg:  map[string]map[string]string{"Say": {"name": "required minlen=2 maxlen=8 pattern=^[a-z]+$"}},
+			checks: map[string][]string{"Say.name": {
+				`r.Name == ""`,
+				"utf8.RuneCountInString(r.Name) < 2",
+				"utf8.RuneCountInString(r.Name) > 8",
+				"!sayNamePattern.MatchString(r.Name)",
+			}},
+		},
+		{
+			name: "optional string",
+			cfg:  map[string]map[string]string{"Say": {"name": "minlen=2 enum=ab|cd"}},
+			checks: map[string][]string{"Say.name": {
+				"len(r.Name) > 0 && utf8.RuneCountInString(r.Name) < 2",
+				`len(r.Name) > 0 && r.Name != "ab" && r.Name != "cd"`,
+			}},
+		},
+		{
+			name:   "numbers and bools",
+			cfg:    map[string]map[string]string{"Get": {"id": "min=1 max=10", "verbose": "required"}},
+			checks: map[string][]string{"Get.id": {"r.ID < 1", "r.ID > 10"}, "Get.verbose": {"!r.Verbose"}},
+		},
+		{
+			name: "float bound of an int",
+			cfg:  map[string]map[string]string{"Get": {"id": "min=1.5"}},
+			err:  "validate: Get id: 1.5 is not an integer",
+		},
+		{
+			name: "length of an int",
+			cfg:  map[string]map[string]string{"Get": {"id": "maxlen=1"}},
+			err:  "type int does not support minlen and maxlen",
+		},
+		{
+			name: "enum of a bool",
+			cfg:  map[string]map[string]string{"Get": {"verbose": "enum=true"}},
+			err:  "type bool does not support enum",
+		},
+		{
+			name: "bounds of a string",
+			cfg:  map[string]map[string]string{"Say": {"name": "min=1"}},
+			err:  "type string does not support min and max",
+		},
+		{
+			name: "unknown param",
+			cfg:  map[string]map[string]string{"Say": {"nope": "required"}},
+			err:  "validate: Say has no params nope",
+		},
+		{
+			name: "unknown method",
+			cfg:  map[string]map[string]string{"Nope": {}},
+			err:  "validate: unknown methods Nope",
+		},
+	}
+	for _, tc := range cases {
+		endpoints := newData(testResult(), nil).Endpoints
+		err := validations(endpoints, tc.cfg)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: got error %v, want %q", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		got := map[string][]string{}
+		for _, e := range endpoints {
+			for _, v := range e.Validation {
+				for _, c := range v.Checks {
+					got[e.Method.Name+"."+v.Field.Field.Name] = append(got[e.Method.Name+"."+v.Field.Field.Name], c.Cond)
+				}
+			}
+		}
+		if len(got) == 0 {
+			got = nil
+		}
+		if !reflect.DeepEqual(got, tc.checks) {
+			t.Errorf("%s: got checks %q, want %q", tc.name, got, tc.checks)
+		}
+	}
+}
+
+func TestValidSample(t *testing.T) {
+	cases := []struct {
+		typ, rule string
+		want      string
+		ok        bool
+	}{
+		{"string", "", `"name"`, true},
+		{"string", "minlen=6", `"namexx"`, true},
+		{"string", "maxlen=2", `"na"`, true},
+		{"string", "enum=a|b", `"a"`, true},
+		{"string", "pattern=^[0-9]+$", `"name"`, false},
+		{"int", "", "2", true},
+		{"int", "min=5", "5", true},
+		{"int", "max=0 required", "0", false},
+		{"float64", "", "2.5", true},
+		{"uint", "max=-1", "", false},
+		{"bool", "required", "true", true},
+		{"[]string", "", "", true},
+		{"[]string", "required", "", false},
+	}
+	for _, tc := range cases {
+		rule, err := parseValidationRule(tc.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := parser.Field{Name: "name", Type: tc.typ}
+		got, ok := validSample(f, validationKind(tc.typ), rule, 1)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("validSample(%s, %q): got %s, %v, want %s, %v", tc.typ, tc.rule, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package imports
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/l-vitaly/gokitgen/pkg/utils"
+)
+
+// Import import spec.
+type Import struct {
+	// Name package name in the file, the path is imported without a name if empty.
+	Name string
+	Path string
+}
+
+// Spec returns the import spec, the name is omitted if it is the last path element.
+func (i Import) Spec() string {
+	elems := strings.Split(i.Path, "/")
+	if i.Name == "" || i.Name == elems[len(elems)-1] {
+		return strconv.Quote(i.Path)
+	}
+	return i.Name + " " + strconv.Quote(i.Path)
+}
+
+// IsStd reports whether the path is of a standard package.
+func (i Import) IsStd() bool {
+	return !strings.Contains(strings.SplitN(i.Path, "/", 2)[0], ".")
+}
+
+// Block renders imports as an import declaration, standard packages go first.
+func Block(imports []Import) string {
+	if len(imports) == 0 {
+		return ""
+	}
+	var std, other []string
+	for _, i := range imports {
+		if i.IsStd() {
+			std = append(std, i.Spec())
+		} else {
+			other = append(other, i.Spec())
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for _, spec := range std {
+		b.WriteString("\t" + spec + "\n")
+	}
+	if len(std) > 0 && len(other) > 0 {
+		b.WriteString("\n")
+	}
+	for _, spec := range other {
+		b.WriteString("\t" + spec + "\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// Manager tracks packages a generated file refers to and assigns them non-conflicting names.
+//
+// Packages are either declared with a fixed name the generated code uses as is, or
+// referenced by types of the service, which are renamed if their names are taken.
+// Referenced packages are named by Resolve, so the code is rendered twice:
+// once to collect the packages and once more with the resolved names.
+type Manager struct {
+	// paths import paths by name
+	paths map[string]string
+	// names names by import path
+	names map[string]string
+	// refs referenced paths with their preferred names, in reference order
+	refs []Import
+}
+
+// Declare declares the package with a fixed name, the last path element by default.
+func (m *Manager) Declare(path, name string) error {
+	if name == "" {
+		name = utils.PackageName(path)
+	}
+	if p, ok := m.paths[name]; ok && p != path {
+		return fmt.Errorf("import %q as %s: the name is taken by %q", path, name, p)
+	}
+	if n, ok := m.names[path]; ok && n != name {
+		return fmt.Errorf("import %q as %s: it is already imported as %s", path, name, n)
+	}
+	m.paths[name] = path
+	m.names[path] = name
+	return nil
+}
+
+// Ref references a package of a service type and returns its name,
+// the preferred name is returned until the package is resolved.
+func (m *Manager) Ref(path, name string) string {
+	if n, ok := m.names[path]; ok {
+		return n
+	}
+	if name == "" {
+		name = utils.PackageName(path)
+	}
+	m.refs = append(m.refs, Import{Name: name, Path: path})
+	return name
+}
+
+// Resolve names referenced packages, a taken name is prefixed with the parent
+// path element or numbered, e.g. "model" becomes "usermodel" or "model2".
+func (m *Manager) Resolve() {
+	for _, ref := range m.refs {
+		if _, ok := m.names[ref.Path]; ok {
+			continue
+		}
+		name := ref.Name
+		if _, ok := m.paths[name]; ok {
+			elems := strings.Split(ref.Path, "/")
+			if len(elems) > 1 {
+				name = utils.PackageName(strings.Join(elems[:len(elems)-1], "/")) + ref.Name
+			}
+		}
+		for i := 2; m.paths[name] != ""; i++ {
+			name = ref.Name + strconv.Itoa(i)
+		}
+		m.paths[name] = ref.Path
+		m.names[ref.Path] = name
+	}
+	m.refs = nil
+}
+
+// Used returns the packages src refers to.
+func (m *Manager) Used(src []byte) ([]Import, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+	var imports []Import
+	for name := range Qualifiers(f) {
+		if path, ok := m.paths[name]; ok {
+			imports = append(imports, Import{Name: name, Path: path})
+		}
+	}
+	return imports, nil
+}
+
+// Qualifiers returns names of the packages f may refer to: identifiers
+// not declared in the file that are used as selector operands.
+func Qualifiers(f *ast.File) map[string]bool {
+	used := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// NewManager creates an import manager.
+func NewManager() *Manager {
+	return &Manager{
+		paths: map[string]string{},
+		names: map[string]string{},
+	}
+}
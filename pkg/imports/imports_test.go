@@ -0,0 +1,96 @@
+package imports
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestManagerDeclare(t *testing.T) {
+	m := NewManager()
+	if err := m.Declare("net/http", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Declare("net/http", "http"); err != nil {
+		t.Errorf("redeclaring the same import: %v", err)
+	}
+	if err := m.Declare("github.com/go-kit/kit/transport/http", ""); err == nil {
+		t.Error("no error for the taken name")
+	}
+	if err := m.Declare("net/http", "stdhttp"); err == nil {
+		t.Error("no error for the import under another name")
+	}
+	if err := m.Declare("github.com/go-kit/kit/transport/http", "kithttp"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestManagerResolve(t *testing.T) {
+	m := NewManager()
+	for _, path := range []string{"context", "example.com/hello/model"} {
+		if err := m.Declare(path, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		path, name   string
+		before, want string
+	}{
+		{"context", "", "context", "context"},
+		{"example.com/user/model", "", "model", "usermodel"},
+		{"example.com/order/model", "", "model", "ordermodel"},
+		{"model", "", "model", "model2"},
+		{"example.com/x/uuid", "guuid", "guuid", "guuid"},
+	}
+	for _, tc := range cases {
+		if got := m.Ref(tc.path, tc.name); got != tc.before {
+			t.Errorf("Ref(%q) before Resolve: got %q, want %q", tc.path, got, tc.before)
+		}
+	}
+	m.Resolve()
+	for _, tc := range cases {
+		if got := m.Ref(tc.path, tc.name); got != tc.want {
+			t.Errorf("Ref(%q) after Resolve: got %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestManagerUsed(t *testing.T) {
+	m := NewManager()
+	m.Declare("context", "")
+	m.Declare("net/http", "")
+	m.Declare("github.com/go-kit/kit/log", "")
+	m.Ref("example.com/user/model", "")
+	m.Resolve()
+
+	src := []byte(`package p
+
+func f(ctx context.Context, log string) model.User {
+	var http struct{ Get int }
+	_ = http.Get
+	return model.User{}
+}
+`)
+	got, err := m.Used(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].Path < got[j].Path })
+	want := []Import{{Name: "context", Path: "context"}, {Name: "model", Path: "example.com/user/model"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Used: got %v, want %v", got, want)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	got := Block([]Import{
+		{Path: "github.com/go-kit/kit/log"},
+		{Name: "kithttp", Path: "github.com/go-kit/kit/transport/http"},
+		{Name: "strings", Path: "strings"},
+		{Path: "context"},
+	})
+	want := "import (\n\t\"context\"\n\t\"strings\"\n\n\t\"github.com/go-kit/kit/log\"\n\tkithttp \"github.com/go-kit/kit/transport/http\"\n)\n"
+	if got != want {
+		t.Errorf("Block: got %q, want %q", got, want)
+	}
+}
@@ -17,7 +17,8 @@ func (l *yamlLoader) Load(filename string) error {
 	if err != nil {
 		return err
 	}
-	err = yaml.Unmarshal(data, l.c)
+	// unknown keys fail instead of being silently ignored
+	err = yaml.UnmarshalStrict(data, l.c)
 	if err != nil {
 		return err
 	}
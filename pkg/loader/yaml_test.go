@@ -0,0 +1,77 @@
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/config"
+)
+
+func loadYAML(t *testing.T, data string) (*config.Config, error) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "loader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, ".gokit.yaml")
+	if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Config{}
+	l := NewYAML()
+	l.SetConfig(c)
+	return c, l.Load(filename)
+}
+
+func TestYAMLCamelCaseKeys(t *testing.T) {
+	c, err := loadYAML(t, `
+service: hello.Service
+json:
+  naming: camel
+  omitEmpty: true
+resilience:
+  defaults:
+    timeout: 2s
+    breaker:
+      failures: 3
+      maxRequests: 2
+    rateLimit:
+      rate: 10
+      burst: 20
+  methods:
+    Say:
+      retry:
+        attempts: 5
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.JSON.OmitEmpty {
+		t.Error("json.omitEmpty is not loaded")
+	}
+	d := c.Resilience.Defaults
+	if d.Breaker == nil || d.Breaker.MaxRequests != 2 {
+		t.Errorf("breaker.maxRequests is not loaded: %+v", d.Breaker)
+	}
+	if d.RateLimit == nil || d.RateLimit.Rate != 10 || d.RateLimit.Burst != 20 {
+		t.Errorf("rateLimit is not loaded: %+v", d.RateLimit)
+	}
+	if r := c.Resilience.Methods["Say"].Retry; r == nil || r.Attempts != 5 {
+		t.Errorf("methods.Say.retry is not loaded: %+v", r)
+	}
+}
+
+func TestYAMLUnknownKeys(t *testing.T) {
+	for _, data := range []string{
+		"json:\n  omitempty: true\n",
+		"resilience:\n  defaults:\n    breaker:\n      maxrequests: 2\n",
+		"resilience:\n  defaults:\n    ratelimit:\n      rate: 1\n",
+	} {
+		if _, err := loadYAML(t, data); err == nil {
+			t.Errorf("no error for unknown key in %q", data)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/l-vitaly/gokitgen/pkg/imports"
+	"github.com/l-vitaly/gokitgen/pkg/utils"
+)
+
+// Result merge result.
+type Result struct {
+	// Src merged source, nil if there is nothing left to write.
+	Src []byte
+	// Added names of the appended stubs.
+	Added []string
+	// Removed names of the user declarations replaced by generated ones.
+	Removed []string
+	// Pruned names of the unedited stubs nothing needs anymore, e.g. of removed service methods.
+	Pruned []string
+}
+
+// EditedError edited user declarations that are generated now, Stubs keeps them unless forced.
+type EditedError struct {
+	Names []string
+}
+
+func (e *EditedError) Error() string {
+	return "edited declarations are generated now: " + strings.Join(e.Names, ", ")
+}
+
+// Stubs merges generated stubs into the user file src, src is nil if the file does not exist yet.
+// Unedited stubs declared by the generated files gen are removed from src, edited declarations are
+// removed only if force is set, otherwise an *EditedError is returned. Unedited stubs declared in
+// neither stubs nor gen are pruned, stubs declared in neither src nor gen are appended, imports are
+// added or dropped as needed. Other user code is kept as is.
+func Stubs(src, stubs []byte, force bool, gen ...[]byte) (Result, error) {
+	var res Result
+
+	fset := token.NewFileSet()
+	genNames := map[string]bool{}
+	for _, data := range gen {
+		genFile, err := parser.ParseFile(fset, "gen.go", data, 0)
+		if err != nil {
+			return res, fmt.Errorf("generated file: %v", err)
+		}
+		for _, d := range genFile.Decls {
+			for _, name := range declNames(d) {
+				genNames[name] = true
+			}
+		}
+	}
+	stubsFile, err := parser.ParseFile(fset, "stubs.go", stubs, parser.ParseComments)
+	if err != nil {
+		return res, fmt.Errorf("stubs: %v", err)
+	}
+	if src == nil {
+		src = []byte("package " + stubsFile.Name.Name + "\n")
+	}
+	srcFile, err := parser.ParseFile(fset, "src.go", src, parser.ParseComments)
+	if err != nil {
+		return res, err
+	}
+
+	stubNames := map[string]bool{}
+	for _, d := range stubsFile.Decls {
+		for _, name := range declNames(d) {
+			stubNames[name] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	var edited []string
+	srcNames := map[string]bool{}
+	offset, kept := 0, 0
+	for _, d := range srcFile.Decls {
+		names := declNames(d)
+		for _, name := range names {
+			srcNames[name] = true
+		}
+		switch {
+		case len(names) > 0 && containsAll(names, genNames):
+			if !force && !isStub(d) {
+				edited = append(edited, names...)
+				kept++
+				continue
+			}
+			res.Removed = append(res.Removed, names...)
+		case isStub(d) && !containsAny(names, stubNames):
+			res.Pruned = append(res.Pruned, names...)
+		default:
+			if d, ok := d.(*ast.GenDecl); !ok || d.Tok != token.IMPORT {
+				kept++
+			}
+			continue
+		}
+		start, end := declRange(fset, d)
+		buf.Write(src[offset:start])
+		offset = end
+	}
+	buf.Write(src[offset:])
+	if len(edited) > 0 {
+		return res, &EditedError{Names: edited}
+	}
+
+	for _, d := range stubsFile.Decls {
+		names := declNames(d)
+		if len(names) == 0 || containsAny(names, srcNames) || containsAny(names, genNames) {
+			continue
+		}
+		start, end := declRange(fset, d)
+		buf.WriteString("\n\n")
+		buf.Write(stubs[start:end])
+		res.Added = append(res.Added, names...)
+	}
+
+	if src, err = fixImports(buf.Bytes(), append(srcFile.Imports, stubsFile.Imports...)); err != nil {
+		return res, err
+	}
+	if kept == 0 && len(res.Added) == 0 {
+		// neither stubs nor user code, the file is not needed
+		return res, nil
+	}
+	if res.Src, err = format.Source(src); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// declNames returns names declared by d, methods are named as Type.Method.
+func declNames(d ast.Decl) []string {
+	var names []string
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			return []string{recvName(d.Recv.List[0].Type) + "." + d.Name.Name}
+		}
+		if d.Name.Name != "init" {
+			names = append(names, d.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, spec.Name.Name)
+			case *ast.ValueSpec:
+				for _, n := range spec.Names {
+					if n.Name != "_" {
+						names = append(names, n.Name)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// isStub reports whether d is an unedited stub: a function only panicking with "not implement <name>",
+// the name is not checked, so stubs copied from each other without renaming the message are stubs too.
+func isStub(d ast.Decl) bool {
+	f, ok := d.(*ast.FuncDecl)
+	if !ok || f.Recv != nil || f.Body == nil || len(f.Body.List) != 1 {
+		return false
+	}
+	stmt, ok := f.Body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return false
+	}
+	if fun, ok := call.Fun.(*ast.Ident); !ok || fun.Name != "panic" {
+		return false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return false
+	}
+	msg, err := strconv.Unquote(lit.Value)
+	return err == nil && strings.HasPrefix(msg, "not implement ")
+}
+
+func recvName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return recvName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// declRange returns byte offsets of d including its doc comment.
+func declRange(fset *token.FileSet, d ast.Decl) (int, int) {
+	pos := d.Pos()
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			pos = d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			pos = d.Doc.Pos()
+		}
+	}
+	return fset.Position(pos).Offset, fset.Position(d.End()).Offset
+}
+
+func containsAll(names []string, set map[string]bool) bool {
+	for _, name := range names {
+		if !set[name] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(names []string, set map[string]bool) bool {
+	for _, name := range names {
+		if set[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// fixImports rewrites the imports of src to the specs the code refers to,
+// src is returned untouched if its imports are already right.
+func fixImports(src []byte, specs []*ast.ImportSpec) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "src.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	used := imports.Qualifiers(f)
+
+	var want []imports.Import
+	seen := map[imports.Import]bool{}
+	for _, spec := range specs {
+		i := importOf(spec)
+		name := i.Name
+		if name == "" {
+			name = utils.PackageName(i.Path)
+		}
+		if name != "_" && name != "." && !used[name] {
+			continue
+		}
+		if !seen[i] {
+			seen[i] = true
+			want = append(want, i)
+		}
+	}
+
+	var have []imports.Import
+	for _, spec := range f.Imports {
+		have = append(have, importOf(spec))
+	}
+	if imports.Block(want) == imports.Block(have) {
+		return src, nil
+	}
+
+	var buf bytes.Buffer
+	offset := fset.Position(f.Name.End()).Offset
+	buf.Write(src[:offset])
+	buf.WriteString("\n\n")
+	buf.WriteString(imports.Block(want))
+	for _, d := range f.Decls {
+		if d, ok := d.(*ast.GenDecl); !ok || d.Tok != token.IMPORT {
+			break
+		}
+		_, offset = declRange(fset, d)
+	}
+	buf.Write(src[offset:])
+	return buf.Bytes(), nil
+}
+
+func importOf(spec *ast.ImportSpec) imports.Import {
+	path, _ := strconv.Unquote(spec.Path.Value)
+	i := imports.Import{Path: path}
+	if spec.Name != nil {
+		i.Name = spec.Name.Name
+	}
+	return i
+}
@@ -0,0 +1,160 @@
+package merge
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const stubs = `package hello
+
+import "context"
+
+func Say(ctx context.Context) error {
+	panic("not implement Say")
+}
+
+func Get(ctx context.Context) error {
+	panic("not implement Get")
+}
+`
+
+func TestStubs(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		force    bool
+		gen      []string
+		want     []string
+		wantNot  []string
+		added    []string
+		removed  []string
+		pruned   []string
+		edited   []string
+		emptySrc bool
+	}{
+		{
+			name:  "new file",
+			want:  []string{`"context"`, "func Say(", "func Get("},
+			added: []string{"Say", "Get"},
+		},
+		{
+			name:    "user code kept",
+			src:     "package hello\n\nimport \"context\"\n\nfunc Say(ctx context.Context) error {\n\treturn nil\n}\n",
+			want:    []string{"return nil", "func Get("},
+			wantNot: []string{`panic("not implement Say")`},
+			added:   []string{"Get"},
+		},
+		{
+			name:    "unedited stub of a generated name removed",
+			src:     stubs,
+			gen:     []string{"package hello\n\nfunc Say() {}\n"},
+			want:    []string{"func Get("},
+			wantNot: []string{"func Say("},
+			removed: []string{"Say"},
+		},
+		{
+			name:   "edited declaration of a generated name kept",
+			src:    "package hello\n\nfunc Say() error {\n\treturn nil\n}\n",
+			gen:    []string{"package hello\n\nfunc Say() {}\n"},
+			edited: []string{"Say"},
+		},
+		{
+			name:    "edited declaration of a generated name forced",
+			src:     "package hello\n\nfunc Say() error {\n\treturn nil\n}\n",
+			force:   true,
+			gen:     []string{"package hello\n\nfunc Say() {}\n"},
+			want:    []string{"func Get("},
+			wantNot: []string{"func Say("},
+			added:   []string{"Get"},
+			removed: []string{"Say"},
+		},
+		{
+			name:    "stale stub pruned",
+			src:     stubs + "\nfunc Old() {\n\tpanic(\"not implement Old\")\n}\n",
+			want:    []string{"func Say(", "func Get("},
+			wantNot: []string{"func Old("},
+			pruned:  []string{"Old"},
+		},
+		{
+			name:    "stale stub with another name in the message pruned",
+			src:     stubs + "\nfunc Old() {\n\tpanic(\"not implement Say\")\n}\n",
+			wantNot: []string{"func Old("},
+			pruned:  []string{"Old"},
+		},
+		{
+			name:    "stub with another name in the message of a generated name removed",
+			src:     "package hello\n\nfunc Say() {\n\tpanic(\"not implement Get\")\n}\n",
+			gen:     []string{"package hello\n\nfunc Say() {}\n"},
+			want:    []string{"func Get("},
+			wantNot: []string{"func Say("},
+			added:   []string{"Get"},
+			removed: []string{"Say"},
+		},
+		{
+			name: "edited stale function kept",
+			src:  stubs + "\nfunc Old() {\n\tprintln(\"old\")\n}\n",
+			want: []string{"func Old("},
+		},
+		{
+			name:     "nothing left",
+			src:      stubs,
+			gen:      []string{"package hello\n\nfunc Say() {}\n\nfunc Get() {}\n"},
+			removed:  []string{"Say", "Get"},
+			emptySrc: true,
+		},
+		{
+			name:    "unused import dropped",
+			src:     "package hello\n\nimport (\n\t\"context\"\n\t\"fmt\"\n)\n\nfunc Say(ctx context.Context) error {\n\tpanic(\"not implement Say\")\n}\n",
+			gen:     []string{"package hello\n\nfunc Say() {}\n"},
+			want:    []string{`"context"`, "func Get("},
+			wantNot: []string{`"fmt"`},
+			added:   []string{"Get"},
+			removed: []string{"Say"},
+		},
+	}
+	for _, tc := range cases {
+		var src []byte
+		if tc.src != "" {
+			src = []byte(tc.src)
+		}
+		var gen [][]byte
+		for _, g := range tc.gen {
+			gen = append(gen, []byte(g))
+		}
+		res, err := Stubs(src, []byte(stubs), tc.force, gen...)
+		if tc.edited != nil {
+			e, ok := err.(*EditedError)
+			if !ok || !reflect.DeepEqual(e.Names, tc.edited) {
+				t.Errorf("%s: got error %v, want edited %q", tc.name, err, tc.edited)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if tc.emptySrc != (res.Src == nil) {
+			t.Errorf("%s: got src %q, want nil %v", tc.name, res.Src, tc.emptySrc)
+		}
+		for _, s := range tc.want {
+			if !strings.Contains(string(res.Src), s) {
+				t.Errorf("%s: %q not found in\n%s", tc.name, s, res.Src)
+			}
+		}
+		for _, s := range tc.wantNot {
+			if strings.Contains(string(res.Src), s) {
+				t.Errorf("%s: %q found in\n%s", tc.name, s, res.Src)
+			}
+		}
+		if !reflect.DeepEqual(res.Added, tc.added) {
+			t.Errorf("%s: added %q, want %q", tc.name, res.Added, tc.added)
+		}
+		if !reflect.DeepEqual(res.Removed, tc.removed) {
+			t.Errorf("%s: removed %q, want %q", tc.name, res.Removed, tc.removed)
+		}
+		if !reflect.DeepEqual(res.Pruned, tc.pruned) {
+			t.Errorf("%s: pruned %q, want %q", tc.name, res.Pruned, tc.pruned)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package naming
+
+import (
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+	"github.com/l-vitaly/gokitgen/pkg/utils"
+)
+
+// initialisms words written in upper case in Go identifiers, see https://go.dev/wiki/CodeReviewComments#initialisms.
+var initialisms = map[string]string{
+	"acl": "ACL", "api": "API", "ascii": "ASCII", "cpu": "CPU", "css": "CSS", "dns": "DNS",
+	"eof": "EOF", "grpc": "GRPC", "guid": "GUID", "html": "HTML", "http": "HTTP", "https": "HTTPS",
+	"id": "ID", "ids": "IDs", "ip": "IP", "json": "JSON", "jwt": "JWT", "lhs": "LHS", "qps": "QPS",
+	"ram": "RAM", "rhs": "RHS", "rpc": "RPC", "sla": "SLA", "smtp": "SMTP", "sql": "SQL",
+	"ssh": "SSH", "tcp": "TCP", "tls": "TLS", "ttl": "TTL", "udp": "UDP", "ui": "UI", "uid": "UID",
+	"uri": "URI", "url": "URL", "urls": "URLs", "utf8": "UTF8", "uuid": "UUID", "vm": "VM",
+	"xml": "XML", "xmpp": "XMPP", "xsrf": "XSRF", "xss": "XSS",
+}
+
+// Exported returns the exported form of the identifier with idiomatic initialisms,
+// e.g. "userId" -> "UserID", "url" -> "URL".
+func Exported(name string) string {
+	var b strings.Builder
+	for _, w := range utils.Words(name) {
+		// initialisms keep their case before a numeric suffix, e.g. "url1" -> "URL1"
+		digits := strings.TrimRight(w, "0123456789")
+		if s, ok := initialisms[strings.ToLower(digits)]; ok {
+			b.WriteString(s + w[len(digits):])
+		} else {
+			b.WriteString(utils.UcFirst(w))
+		}
+	}
+	return b.String()
+}
+
+// Unexported returns the unexported form of the identifier with idiomatic initialisms,
+// e.g. "ID" -> "id", "HTTPErrors" -> "httpErrors", "UserID" -> "userID".
+func Unexported(name string) string {
+	words := utils.Words(name)
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.ToLower(words[0]) + Exported(strings.Join(words[1:], "_"))
+}
+
+// predeclared identifiers of the universe scope.
+var predeclared = []string{
+	"any", "bool", "byte", "comparable", "complex64", "complex128", "error", "float32", "float64",
+	"int", "int8", "int16", "int32", "int64", "rune", "string", "uint", "uint8", "uint16", "uint32",
+	"uint64", "uintptr", "true", "false", "iota", "nil", "append", "cap", "clear", "close", "complex",
+	"copy", "delete", "imag", "len", "make", "max", "min", "new", "panic", "print", "println", "real",
+	"recover",
+}
+
+// generated identifiers the generated code declares in the scopes of the service method params and results:
+// receivers, locals and names of the packages imported by the built-in templates.
+var generated = []string{
+	"s", "m", "r", "w", "u", "req", "request", "resp", "response", "err", "ctx", "opts", "begin",
+	"next", "logger", "svc", "called", "tc", "t", "v", "q", "vars", "buf",
+	"bytes", "context", "endpoint", "errors", "fmt", "http", "httptest", "ioutil", "json", "kithttp",
+	"log", "mux", "reflect", "reporter", "stdzipkin", "strconv", "strings", "testing", "time", "url",
+	"zipkin",
+}
+
+var reserved = map[string]bool{}
+
+func init() {
+	for _, name := range predeclared {
+		reserved[name] = true
+	}
+	for _, name := range generated {
+		reserved[name] = true
+	}
+}
+
+// IsReserved reports whether the name cannot be given to a service method param or result
+// in generated code: Go keywords, predeclared identifiers and identifiers of the generated code.
+func IsReserved(name string) bool {
+	return token.IsKeyword(name) || reserved[name]
+}
+
+// Resolve renames params and results of the service methods clashing with reserved names, with
+// names of the packages their types refer to or with each other. Renamed fields keep the declared
+// name in Field.Declared. The names only depend on the method, so every generator renames them alike.
+func Resolve(result parser.Result) parser.Result {
+	methods := make([]parser.Method, len(result.Methods))
+	for i, m := range result.Methods {
+		m.Params = append([]parser.Field(nil), m.Params...)
+		m.Results = append([]parser.Field(nil), m.Results...)
+
+		pkgs := map[string]bool{}
+		for _, f := range append(append([]parser.Field(nil), m.Params...), m.Results...) {
+			for name := range f.Imports {
+				pkgs[name] = true
+			}
+		}
+		taken := map[string]bool{}
+		for _, f := range append(append([]parser.Field(nil), m.Params...), m.Results...) {
+			taken[f.Name] = true
+		}
+		rename := func(f *parser.Field) {
+			if !clashes(*f, pkgs) {
+				return
+			}
+			base := f.Name
+			if base == "_" {
+				base = "unused"
+			}
+			for n := 1; ; n++ {
+				name := base + strconv.Itoa(n)
+				if !taken[name] && !IsReserved(name) && !pkgs[name] {
+					taken[name] = true
+					f.Declared, f.Name = f.Name, name
+					return
+				}
+			}
+		}
+		for j := range m.Params {
+			rename(&m.Params[j])
+		}
+		for j := range m.Results {
+			rename(&m.Results[j])
+		}
+		methods[i] = m
+	}
+	result.Methods = methods
+	return result
+}
+
+// clashes reports whether the field name clashes with the generated code,
+// ctx context params and err error results are what the generated code expects.
+func clashes(f parser.Field, pkgs map[string]bool) bool {
+	switch {
+	case f.Name == "ctx" && f.IsContext(), f.Name == "err" && f.IsError():
+		return false
+	case f.Name == "_":
+		return true
+	}
+	return IsReserved(f.Name) || pkgs[f.Name]
+}
+
+// FieldNames returns unique exported struct field names of the fields,
+// names differing only in case get numeric suffixes, e.g. "name", "Name" -> "Name", "Name2".
+func FieldNames(fields []parser.Field) []string {
+	taken := map[string]bool{}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		name := Exported(f.Name)
+		if name == "" {
+			name = "Field"
+		}
+		for n := 2; taken[name]; n++ {
+			name = Exported(f.Name) + strconv.Itoa(n)
+		}
+		taken[name] = true
+		names[i] = name
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+package naming
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/l-vitaly/gokitgen/pkg/parser"
+)
+
+func TestExported(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"userId", "UserID"},
+		{"url", "URL"},
+		{"url1", "URL1"},
+		{"userIDs", "UserIDs"},
+		{"name", "Name"},
+	}
+	for _, tc := range cases {
+		if got := Exported(tc.in); got != tc.want {
+			t.Errorf("Exported(%q): got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestUnexported(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"ID", "id"},
+		{"HTTPErrors", "httpErrors"},
+		{"UserID", "userID"},
+	}
+	for _, tc := range cases {
+		if got := Unexported(tc.in); got != tc.want {
+			t.Errorf("Unexported(%q): got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	ctx := parser.Field{Name: "ctx", Type: "context.Context", Imports: map[string]string{"context": "context"}}
+	cases := []struct {
+		name           string
+		params         []parser.Field
+		results        []parser.Field
+		params2        []string
+		results2       []string
+		declared       []string
+		declaredResult []string
+	}{
+		{
+			name:     "no clashes",
+			params:   []parser.Field{ctx, {Name: "name", Type: "string"}},
+			results:  []parser.Field{{Name: "greeting", Type: "string"}, {Name: "err", Type: "error"}},
+			params2:  []string{"ctx", "name"},
+			results2: []string{"greeting", "err"},
+		},
+		{
+			name:           "reserved names",
+			params:         []parser.Field{ctx, {Name: "req", Type: "string"}, {Name: "string", Type: "string"}},
+			results:        []parser.Field{{Name: "err", Type: "string"}},
+			params2:        []string{"ctx", "req1", "string1"},
+			results2:       []string{"err1"},
+			declared:       []string{"", "req", "string"},
+			declaredResult: []string{"err"},
+		},
+		{
+			name:           "package names and taken suffixes",
+			params:         []parser.Field{{Name: "model", Type: "model.User", Imports: map[string]string{"model": "example.com/model"}}, {Name: "model1", Type: "int"}},
+			results:        []parser.Field{{Name: "_", Type: "error"}},
+			params2:        []string{"model2", "model1"},
+			results2:       []string{"unused1"},
+			declared:       []string{"model", ""},
+			declaredResult: []string{"_"},
+		},
+	}
+	for _, tc := range cases {
+		in := parser.Result{Methods: []parser.Method{{Name: "Say", Params: tc.params, Results: tc.results}}}
+		out := Resolve(in)
+		m := out.Methods[0]
+		if got := fieldNames(m.Params, false); !reflect.DeepEqual(got, tc.params2) {
+			t.Errorf("%s: params %q, want %q", tc.name, got, tc.params2)
+		}
+		if got := fieldNames(m.Results, false); !reflect.DeepEqual(got, tc.results2) {
+			t.Errorf("%s: results %q, want %q", tc.name, got, tc.results2)
+		}
+		if tc.declared == nil {
+			tc.declared = make([]string, len(tc.params))
+		}
+		if got := fieldNames(m.Params, true); !reflect.DeepEqual(got, tc.declared) {
+			t.Errorf("%s: declared params %q, want %q", tc.name, got, tc.declared)
+		}
+		if tc.declaredResult == nil {
+			tc.declaredResult = make([]string, len(tc.results))
+		}
+		if got := fieldNames(m.Results, true); !reflect.DeepEqual(got, tc.declaredResult) {
+			t.Errorf("%s: declared results %q, want %q", tc.name, got, tc.declaredResult)
+		}
+		if in.Methods[0].Params[len(tc.params)-1].Declared != "" {
+			t.Errorf("%s: the input fields are modified", tc.name)
+		}
+	}
+}
+
+func fieldNames(fields []parser.Field, declared bool) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		if declared {
+			names[i] = f.Declared
+		} else {
+			names[i] = f.Name
+		}
+	}
+	return names
+}
+
+func TestFieldNames(t *testing.T) {
+	fields := []parser.Field{{Name: "name"}, {Name: "Name"}, {Name: "userId"}, {Name: "_"}}
+	want := []string{"Name", "Name2", "UserID", "Field"}
+	if got := FieldNames(fields); !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldNames: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,387 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/l-vitaly/gokitgen/pkg/check"
+)
+
+// WriterOption writer option.
+type WriterOption func(w *Writer)
+
+// WriterDryRun prints files instead of writing them.
+func WriterDryRun(dryRun bool) WriterOption {
+	return func(w *Writer) {
+		w.dryRun = dryRun
+	}
+}
+
+// WriterDiff prints unified diffs against the files on disk instead of writing them.
+func WriterDiff(diff bool) WriterOption {
+	return func(w *Writer) {
+		w.diff = diff
+	}
+}
+
+// WriterCheck reports stale files instead of writing them, see Writer.Err.
+func WriterCheck(check bool) WriterOption {
+	return func(w *Writer) {
+		w.check = check
+	}
+}
+
+// WriterForce overwrites files lacking the generated code header.
+func WriterForce(force bool) WriterOption {
+	return func(w *Writer) {
+		w.force = force
+	}
+}
+
+// WriterTypeCheck type-checks packages of the generated Go files before they are written.
+func WriterTypeCheck(typeCheck bool) WriterOption {
+	return func(w *Writer) {
+		w.typeCheck = typeCheck
+	}
+}
+
+// WriterLoader loader the generated Go files are type-checked with, a new one per flush by default.
+func WriterLoader(loader *check.Loader) WriterOption {
+	return func(w *Writer) {
+		w.loader = loader
+	}
+}
+
+// WriterOutput destination of the printed files and diffs, os.Stdout by default.
+func WriterOutput(out io.Writer) WriterOption {
+	return func(w *Writer) {
+		w.out = out
+	}
+}
+
+// Writer writes generated files. Files are staged by Write and WriteGenerated
+// and written by Flush, only if none of the dry run, diff and check modes is enabled.
+type Writer struct {
+	out       io.Writer
+	dryRun    bool
+	diff      bool
+	check     bool
+	force     bool
+	typeCheck bool
+	loader    *check.Loader
+	files     []file
+	stale     []string
+}
+
+type file struct {
+	generator string
+	name      string
+	data      []byte
+}
+
+// Header marks Go files as generated, see https://golang.org/s/generatedcode.
+const Header = "// Code generated by gokitgen. DO NOT EDIT.\n\n"
+
+var headerRegexp = regexp.MustCompile(`(?m)^// Code generated by gokitgen\. DO NOT EDIT\.$`)
+
+// IsGenerated reports whether src has the generated code header.
+func IsGenerated(src []byte) bool {
+	return headerRegexp.Match(src)
+}
+
+// WriteGenerated stages a fully generated file of the generator. Go files get the generated code
+// header, existing Go files without the header are only overwritten with the force option.
+func (w *Writer) WriteGenerated(generator, filename string, data []byte) error {
+	if filepath.Ext(filename) != ".go" {
+		return w.Write(generator, filename, data)
+	}
+	current, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && !w.force && !IsGenerated(current) {
+		return fmt.Errorf("%s was not generated by gokitgen, use --force to overwrite it", filename)
+	}
+	return w.Write(generator, filename, append([]byte(Header), data...))
+}
+
+// Write stages data of the generator to be written to filename, nil data removes the file.
+func (w *Writer) Write(generator, filename string, data []byte) error {
+	for i, f := range w.files {
+		if f.name == filename {
+			w.files = append(w.files[:i], w.files[i+1:]...)
+			break
+		}
+	}
+	w.files = append(w.files, file{generator: generator, name: filename, data: data})
+	return nil
+}
+
+// Flush type-checks and writes the staged files. Files with the same content are left untouched.
+// The files are written as a whole: if any file fails to be written, the written ones are restored.
+func (w *Writer) Flush() error {
+	files := w.files
+	w.files = nil
+	if w.typeCheck {
+		loader := w.loader
+		if loader == nil {
+			loader = check.NewLoader()
+		}
+		if err := typeCheck(loader, files, w.out); err != nil {
+			return err
+		}
+	}
+	var changes []change
+	for _, f := range files {
+		c, err := w.change(f.name, f.data)
+		if err != nil {
+			return err
+		}
+		if c != nil {
+			changes = append(changes, *c)
+		}
+	}
+	if !w.Writes() {
+		return nil
+	}
+	return commit(changes)
+}
+
+// change file change, old is nil if the file does not exist.
+type change struct {
+	name string
+	old  []byte
+	data []byte
+	temp string
+}
+
+// change returns the change of filename, nil if its content is data already.
+func (w *Writer) change(filename string, data []byte) (*change, error) {
+	current, err := ioutil.ReadFile(filename)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if (data == nil && !exists) || (data != nil && exists && bytes.Equal(current, data)) {
+		return nil, nil
+	}
+
+	w.stale = append(w.stale, filename)
+	if w.dryRun {
+		if data == nil {
+			fmt.Fprintf(w.out, "remove %s\n", filename)
+		} else {
+			fmt.Fprintf(w.out, "write %s\n%s\n", filename, data)
+		}
+	}
+	if w.diff {
+		fmt.Fprint(w.out, Diff(filename, current, data))
+	}
+	if w.check {
+		fmt.Fprintf(w.out, "%s is stale\n", filename)
+	}
+	if !exists {
+		current = nil
+	}
+	return &change{name: filename, old: current, data: data}, nil
+}
+
+// commit stages the changed files in temporary files first, then renames them in place
+// and removes the deleted files, on failure the files already changed are restored.
+func commit(changes []change) error {
+	var err error
+	for i := range changes {
+		if changes[i].data == nil {
+			continue
+		}
+		if changes[i].temp, err = tempFile(changes[i].name, changes[i].data); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		for _, c := range changes {
+			if c.temp != "" {
+				os.Remove(c.temp)
+			}
+		}
+		return err
+	}
+
+	for i, c := range changes {
+		if c.data == nil {
+			err = os.Remove(c.name)
+		} else {
+			err = os.Rename(c.temp, c.name)
+		}
+		if err == nil {
+			continue
+		}
+		for _, c := range changes[i:] {
+			if c.temp != "" {
+				os.Remove(c.temp)
+			}
+		}
+		for _, c := range changes[:i] {
+			if rerr := restore(c); rerr != nil {
+				log.Printf("%s: %v", c.name, rerr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// restore restores the content of a changed file.
+func restore(c change) error {
+	if c.old == nil {
+		return os.Remove(c.name)
+	}
+	return writeFile(c.name, c.old)
+}
+
+// typeCheck type-checks packages of the Go files, errors in the files are reported with their generators.
+// Errors in other files of the packages fail the check too, unless the files had them before, those
+// are only reported to out, so a package broken by hand does not block the generation.
+func typeCheck(loader *check.Loader, files []file, out io.Writer) error {
+	overlays := map[string]map[string][]byte{}
+	generators := map[string]string{}
+	for _, f := range files {
+		if filepath.Ext(f.name) != ".go" {
+			continue
+		}
+		name, err := filepath.Abs(f.name)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(name)
+		if overlays[dir] == nil {
+			overlays[dir] = map[string][]byte{}
+		}
+		overlays[dir][name] = f.data
+		generators[name] = f.generator
+	}
+
+	var msgs []string
+	for dir, overlay := range overlays {
+		errs, err := loader.Package(dir, overlay)
+		if err != nil {
+			return err
+		}
+		var existing map[string]bool
+		for _, e := range errs {
+			pos := e.Fset.Position(e.Pos)
+			if generator, ok := generators[pos.Filename]; ok {
+				msgs = append(msgs, fmt.Sprintf("%s: %s (%s generator)", pos, e.Msg, generator))
+				continue
+			}
+			if existing == nil {
+				if existing, err = packageErrors(loader, dir); err != nil {
+					return err
+				}
+			}
+			msg := fmt.Sprintf("%s: %s", pos, e.Msg)
+			if existing[msg] {
+				fmt.Fprintf(out, "%s (the file had the error before)\n", msg)
+				continue
+			}
+			msgs = append(msgs, msg+" (broken by the generated files)")
+		}
+	}
+	if len(msgs) > 0 {
+		sort.Strings(msgs)
+		return fmt.Errorf("generated code does not compile:\n%s", strings.Join(msgs, "\n"))
+	}
+	return nil
+}
+
+// packageErrors returns the type errors of the package on disk formatted as "pos: msg".
+func packageErrors(loader *check.Loader, dir string) (map[string]bool, error) {
+	errs, err := loader.Package(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	msgs := map[string]bool{}
+	for _, e := range errs {
+		msgs[fmt.Sprintf("%s: %s", e.Fset.Position(e.Pos), e.Msg)] = true
+	}
+	return msgs, nil
+}
+
+// writeFile writes data to a temporary file renamed to filename,
+// so readers never see a partially written file.
+func writeFile(filename string, data []byte) error {
+	temp, err := tempFile(filename, data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(temp, filename); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return nil
+}
+
+// tempFile writes data to a temporary file next to filename.
+func tempFile(filename string, data []byte) (string, error) {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Writes reports whether files are written to disk.
+func (w *Writer) Writes() bool {
+	return !w.dryRun && !w.diff && !w.check
+}
+
+// Force reports whether the force option is set.
+func (w *Writer) Force() bool {
+	return w.force
+}
+
+// Changed returns names of the files differing from the generated ones,
+// they are changed unless files are not written.
+func (w *Writer) Changed() []string {
+	return append([]string(nil), w.stale...)
+}
+
+// Err returns an error in the check mode if any file differs from the generated one.
+func (w *Writer) Err() error {
+	if !w.check || len(w.stale) == 0 {
+		return nil
+	}
+	return fmt.Errorf("generated files are stale: %s", strings.Join(w.stale, ", "))
+}
+
+// NewWriter creates a writer.
+func NewWriter(options ...WriterOption) *Writer {
+	w := &Writer{out: os.Stdout}
+	for _, o := range options {
+		o(w)
+	}
+	return w
+}
@@ -0,0 +1,152 @@
+package output
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteGeneratedForce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	generated := filepath.Join(dir, "generated.go")
+	edited := filepath.Join(dir, "edited.go")
+	notes := filepath.Join(dir, "notes.md")
+	files := map[string]string{
+		generated: Header + "package p\n",
+		edited:    "package p\n\n// hand-written\n",
+		notes:     "notes\n",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		filename string
+		force    bool
+		ok       bool
+	}{
+		{filepath.Join(dir, "new.go"), false, true},
+		{generated, false, true},
+		{edited, false, false},
+		{edited, true, true},
+		{notes, false, true},
+	}
+	for _, tc := range cases {
+		w := NewWriter(WriterForce(tc.force))
+		err := w.WriteGenerated("test", tc.filename, []byte("package p\n"))
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("WriteGenerated(%s) with force %v: got error %v, want ok %v", filepath.Base(tc.filename), tc.force, err, tc.ok)
+			continue
+		}
+		if !tc.ok {
+			continue
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(tc.filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Ext(tc.filename) == ".go" && !IsGenerated(data) {
+			t.Errorf("%s: the header is missing:\n%s", filepath.Base(tc.filename), data)
+		}
+	}
+	data, err := ioutil.ReadFile(notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "package p\n" {
+		t.Errorf("notes.md: got %q, want it written without the header", data)
+	}
+}
+
+func TestFlushTypeCheck(t *testing.T) {
+	cases := []struct {
+		name   string
+		user   string
+		gen    string
+		errs   []string
+		out    string
+		broken bool
+	}{
+		{
+			name: "compiles",
+			user: "package p\n\nfunc use() string { return Gen() }\n",
+			gen:  "package p\n\nfunc Gen() string { return \"\" }\n",
+		},
+		{
+			name:   "generated file",
+			user:   "package p\n",
+			gen:    "package p\n\nvar V int = \"v\"\n",
+			errs:   []string{"gen.go:5:13", "(test generator)"},
+			broken: true,
+		},
+		{
+			name:   "user file broken by the generated one",
+			user:   "package p\n\nfunc use() string { return Gen() }\n",
+			gen:    "package p\n\nfunc Gen() int { return 0 }\n",
+			errs:   []string{"user.go:3:28", "(broken by the generated files)"},
+			broken: true,
+		},
+		{
+			name: "user file broken before",
+			user: "package p\n\nvar v int = \"v\"\n",
+			gen:  "package p\n\nfunc Gen() int { return 0 }\n",
+			out:  "user.go:3:13: cannot use \"v\"",
+		},
+	}
+	for _, tc := range cases {
+		dir, err := ioutil.TempDir("", "output")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		files := map[string]string{
+			"go.mod":  "module example.com/p\n\ngo 1.18\n",
+			"user.go": tc.user,
+			"gen.go":  Header + "package p\n",
+		}
+		for name, data := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var out bytes.Buffer
+		w := NewWriter(WriterTypeCheck(true), WriterOutput(&out))
+		if err := w.WriteGenerated("test", filepath.Join(dir, "gen.go"), []byte(tc.gen)); err != nil {
+			t.Fatal(err)
+		}
+		err = w.Flush()
+		if broken := err != nil; broken != tc.broken {
+			t.Errorf("%s: got error %v, want broken %v", tc.name, err, tc.broken)
+			continue
+		}
+		for _, want := range tc.errs {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: %q not found in the error:\n%v", tc.name, want, err)
+			}
+		}
+		if !strings.Contains(out.String(), tc.out) {
+			t.Errorf("%s: %q not found in the output:\n%s", tc.name, tc.out, out.String())
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, "gen.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written := string(data) == Header+tc.gen; written == tc.broken {
+			t.Errorf("%s: gen.go written %v, want %v", tc.name, written, !tc.broken)
+		}
+	}
+}
@@ -6,173 +6,358 @@ import (
 	"go/build"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"path/filepath"
 	"strconv"
 	"strings"
-)
 
-type MapImport map[string]string
+	"github.com/l-vitaly/gokitgen/pkg/utils"
+)
 
-func (m MapImport) Add(name string) {
-	i := strings.LastIndex(name, "/")
-	m[name[i+1:]] = name
+type Result struct {
+	Pkg         string   `json:"pkg"`
+	Root        string   `json:"root"`
+	ServiceName string   `json:"serviceName"`
+	Methods     []Method `json:"methods"`
+	// Name service name, the name option of the //gokit:service directive or the interface name.
+	Name string `json:"name"`
+	// Transports transports option of the directive, the service is generated for all transports if empty.
+	Transports []string `json:"transports,omitempty"`
+	// Options other options of the directive.
+	Options map[string]string `json:"options,omitempty"`
+	// Prefix prefix of the generated identifiers and file names, set if the package hosts several services.
+	Prefix string `json:"prefix,omitempty"`
+	// Doc doc comment of the interface without directives.
+	Doc string `json:"doc,omitempty"`
+	// TypeParams type parameters of a generic interface, Type is the constraint.
+	TypeParams []Field `json:"typeParams,omitempty"`
 }
 
-func (m MapImport) Get(pkg string) (val string, ok bool) {
-	val, ok = m[pkg]
-	return
+// TypeString returns the service type, instantiated with its type parameters if the interface is generic.
+func (r Result) TypeString() string {
+	if len(r.TypeParams) == 0 {
+		return r.ServiceName
+	}
+	var names []string
+	for _, p := range r.TypeParams {
+		names = append(names, p.Name)
+	}
+	return r.ServiceName + "[" + strings.Join(names, ", ") + "]"
 }
 
-type Result struct {
-	Pkg         string
-	Root        string
-	ServiceName string
-	MapImport   MapImport
-	Methods     []Method
+// HasTransport reports whether the service is generated for the transport.
+func (r Result) HasTransport(name string) bool {
+	if len(r.Transports) == 0 {
+		return true
+	}
+	for _, t := range r.Transports {
+		if t == name {
+			return true
+		}
+	}
+	return false
 }
 
 type Method struct {
-	Name    string
-	Params  []Field
-	Results []Field
+	Name    string  `json:"name"`
+	Params  []Field `json:"params"`
+	Results []Field `json:"results"`
+	// Doc doc comment of the method.
+	Doc string `json:"doc,omitempty"`
+	// Directives //gokit: comment directives of the method, e.g. "//gokit:validate name required".
+	Directives []string `json:"directives,omitempty"`
 }
 
 type Field struct {
-	Name string
-	Type string
-	Pkg  string
+	Name string `json:"name"`
+	// Type type expression as written in the service source, e.g. "[]*model.User",
+	// the slice type of a variadic param.
+	Type string `json:"type"`
+	// Imports import paths of the packages Type refers to by their names in the source.
+	Imports map[string]string `json:"imports,omitempty"`
+	// Variadic the field is a variadic param.
+	Variadic bool `json:"variadic,omitempty"`
+	// Declared name of the field in the service interface if generators renamed it, see naming.Resolve.
+	Declared string `json:"declared,omitempty"`
 }
 
-func (f Field) TypeWithPkg() string {
-	if f.Pkg != "" {
-		return f.Pkg + "." + f.Type
+// DeclaredName returns the name of the field in the service interface.
+func (f Field) DeclaredName() string {
+	if f.Declared != "" {
+		return f.Declared
 	}
-	return f.Type
+	return f.Name
 }
 
-type Parser struct {
+// IsContext reports whether the field is a context.Context.
+func (f Field) IsContext() bool {
+	i := strings.Index(f.Type, ".")
+	return i > 0 && f.Type[i:] == ".Context" && f.Imports[f.Type[:i]] == "context"
 }
 
-func (p *Parser) getIdentNameWithPrefix(idents []*ast.Ident, def string) string {
-	if len(idents) > 0 {
-		return idents[0].Name
-	}
-	return def
+// IsError reports whether the field is an error.
+func (f Field) IsError() bool {
+	return f.Type == "error"
 }
 
-func (p *Parser) getParamInfo(expr ast.Expr) (pkg string, t string) {
-	fmt.Println(expr)
+type Parser struct {
+}
 
-	switch t := expr.(type) {
-	case *ast.Ident:
-		if t.Obj != nil {
-			return "", t.Obj.Name
+// getTypeInfo returns the type expression and import paths of the packages it refers to.
+func (p *Parser) getTypeInfo(expr ast.Expr, fileImports map[string]string) (string, map[string]string) {
+	imports := map[string]string{}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				if path, ok := fileImports[id.Name]; ok {
+					imports[id.Name] = path
+				}
+			}
 		}
-		return "", t.Name
-	case *ast.SelectorExpr:
-		return t.X.(*ast.Ident).Name, t.Sel.Name
-	case *ast.ArrayType:
-		pkg, tp := p.getParamInfo(t.Elt)
-		return pkg, "[]" + tp
-	}
-
-	panic("panic type")
+		return true
+	})
+	return types.ExprString(expr), imports
 }
 
-func (p *Parser) extractFieldList(pkg string, fieldList *ast.FieldList, defPrefix string) []Field {
+func (p *Parser) extractFieldList(fieldList *ast.FieldList, fileImports map[string]string, defPrefix string) []Field {
 	var result []Field
 
 	if fieldList != nil {
 		for i, param := range fieldList.List {
-
 			f := Field{}
 
-			f.Name = p.getIdentNameWithPrefix(param.Names, defPrefix+strconv.Itoa(i+1))
-
-			pkg, t := p.getParamInfo(param.Type)
-			f.Type = t
-			f.Pkg = pkg
+			typ := param.Type
+			if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+				f.Variadic = true
+				typ = &ast.ArrayType{Elt: ellipsis.Elt}
+			}
+			f.Type, f.Imports = p.getTypeInfo(typ, fileImports)
 
-			result = append(result, f)
+			if len(param.Names) == 0 {
+				f.Name = defPrefix + strconv.Itoa(i+1)
+				result = append(result, f)
+				continue
+			}
+			for _, name := range param.Names {
+				f.Name = name.Name
+				result = append(result, f)
+			}
 		}
 	}
 	return result
 }
 
+// fileImports returns import paths of the file by package names.
+func (p *Parser) fileImports(file *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := utils.PackageName(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "_" && name != "." {
+			imports[name] = path
+		}
+	}
+	return imports
+}
+
 func (p *Parser) getRoot(pkgDir string) (string, error) {
 	goSrc := build.Default.GOPATH + "/src/"
 	return pkgDir[len(goSrc):], nil
 }
 
+// Directive comment directive marking service interfaces, it is followed by key=value options,
+// e.g. //gokit:service name=Greeter transports=http.
+const Directive = "//gokit:service"
+
+// Parse parses the serviceIface interface, e.g. "pkg.Service", of the package in basePath.
 func (p *Parser) Parse(basePath, serviceIface string) (Result, error) {
-	pkg, err := build.Default.ImportDir(basePath, 0)
+	var result Result
+	err := p.walk(basePath, func(pkg *build.Package, root string, spec *ast.TypeSpec, doc *ast.CommentGroup, fileImports map[string]string) error {
+		if pkg.Name+"."+spec.Name.Name != serviceIface {
+			return nil
+		}
+		result = Result{Root: root}
+		r, err := p.service(pkg, root, spec, doc, fileImports)
+		if err != nil {
+			return err
+		}
+		if r.ServiceName != "" {
+			result = r
+		}
+		return nil
+	})
 	if err != nil {
 		return Result{}, err
 	}
+	if result.Root == "" {
+		result.Root, err = p.getRoot(basePath)
+	}
+	return result, err
+}
 
-	root, err := p.getRoot(pkg.Dir)
+// Discover parses the interfaces of the package in basePath marked by the //gokit:service directive.
+// Services of a package hosting several of them get prefixes of their names.
+func (p *Parser) Discover(basePath string) ([]Result, error) {
+	var results []Result
+	err := p.walk(basePath, func(pkg *build.Package, root string, spec *ast.TypeSpec, doc *ast.CommentGroup, fileImports map[string]string) error {
+		if directive(doc) == nil {
+			return nil
+		}
+		r, err := p.service(pkg, root, spec, doc, fileImports)
+		if err != nil {
+			return err
+		}
+		if r.ServiceName == "" {
+			return fmt.Errorf("%s: %s is not an interface", Directive, spec.Name.Name)
+		}
+		results = append(results, r)
+		return nil
+	})
 	if err != nil {
-		return Result{}, err
+		return nil, err
 	}
-	result := Result{
-		MapImport: MapImport(map[string]string{}),
-		Root:      root,
+	if len(results) > 1 {
+		names := map[string]string{}
+		for i, r := range results {
+			if other, ok := names[r.Name]; ok {
+				return nil, fmt.Errorf("services %s and %s have the same name %s", other, r.ServiceName, r.Name)
+			}
+			names[r.Name] = r.ServiceName
+			results[i].Prefix = utils.UcFirst(r.Name)
+		}
 	}
-	result.MapImport.Add(root)
+	return results, nil
+}
 
-	for _, imp := range pkg.Imports {
-		result.MapImport.Add(imp)
+// walk calls fn for every type declared in the package in basePath, doc is the doc comment of the type.
+func (p *Parser) walk(basePath string, fn func(pkg *build.Package, root string, spec *ast.TypeSpec, doc *ast.CommentGroup, fileImports map[string]string) error) error {
+	pkg, err := build.Default.ImportDir(basePath, 0)
+	if err != nil {
+		return err
 	}
+	root, err := p.getRoot(pkg.Dir)
+	if err != nil {
+		return err
+	}
+
 	fs := token.NewFileSet()
 	for _, name := range pkg.GoFiles {
-
 		name = filepath.Join(basePath, name)
-
-		if !strings.HasSuffix(name, ".go") {
-			continue
-		}
-		parsedFile, err := parser.ParseFile(fs, name, nil, 0)
+		parsedFile, err := parser.ParseFile(fs, name, nil, parser.ParseComments)
 		if err != nil {
-			return Result{}, nil
+			return err
 		}
+		fileImports := p.fileImports(parsedFile)
 
 		for _, d := range parsedFile.Decls {
-			if g, ok := d.(*ast.GenDecl); ok {
-				for _, s := range g.Specs {
-					typeSpec, ok := s.(*ast.TypeSpec)
-					if !ok {
-						continue
-					}
-					name := pkg.Name + "." + typeSpec.Name.Name
-
-					if name == serviceIface {
-						result.Pkg = pkg.Name
-						result.ServiceName = typeSpec.Name.Name
-
-						ifaceType, ok := typeSpec.Type.(*ast.InterfaceType)
-						if !ok {
-							continue
-						}
-
-						for _, f := range ifaceType.Methods.List {
-							funcType, ok := f.Type.(*ast.FuncType)
-							if !ok {
-								continue
-							}
-
-							params := p.extractFieldList(pkg.Name, funcType.Params, "param")
-							results := p.extractFieldList(pkg.Name, funcType.Results, "result")
-
-							result.Methods = append(result.Methods, Method{
-								Name:    f.Names[0].Name,
-								Params:  params,
-								Results: results,
-							})
-						}
-					}
+			g, ok := d.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, s := range g.Specs {
+				typeSpec, ok := s.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				doc := typeSpec.Doc
+				if doc == nil && len(g.Specs) == 1 {
+					doc = g.Doc
+				}
+				if err := fn(pkg, root, typeSpec, doc, fileImports); err != nil {
+					return fmt.Errorf("%s: %v", fs.Position(typeSpec.Pos()), err)
 				}
 			}
 		}
 	}
+	return nil
+}
+
+// service returns the service of the interface type, the result has no ServiceName if the type is not an interface.
+func (p *Parser) service(pkg *build.Package, root string, spec *ast.TypeSpec, doc *ast.CommentGroup, fileImports map[string]string) (Result, error) {
+	result := Result{Root: root}
+	ifaceType, ok := spec.Type.(*ast.InterfaceType)
+	if !ok {
+		return result, nil
+	}
+	result.Pkg = pkg.Name
+	result.ServiceName = spec.Name.Name
+	result.Name = spec.Name.Name
+	result.Doc = doc.Text()
+	result.TypeParams = p.extractFieldList(spec.TypeParams, fileImports, "T")
+
+	if options := directive(doc); options != nil {
+		for _, o := range options {
+			i := strings.Index(o, "=")
+			if i <= 0 {
+				return result, fmt.Errorf("%s: option %q is not key=value", Directive, o)
+			}
+			key, value := o[:i], o[i+1:]
+			switch key {
+			case "name":
+				if !token.IsIdentifier(value) {
+					return result, fmt.Errorf("%s: name %q is not an identifier", Directive, value)
+				}
+				result.Name = value
+			case "transports":
+				result.Transports = strings.Split(value, ",")
+			default:
+				if result.Options == nil {
+					result.Options = map[string]string{}
+				}
+				result.Options[key] = value
+			}
+		}
+	}
+
+	for _, f := range ifaceType.Methods.List {
+		funcType, ok := f.Type.(*ast.FuncType)
+		if !ok {
+			continue
+		}
+
+		params := p.extractFieldList(funcType.Params, fileImports, "param")
+		results := p.extractFieldList(funcType.Results, fileImports, "result")
+
+		result.Methods = append(result.Methods, Method{
+			Name:       f.Names[0].Name,
+			Params:     params,
+			Results:    results,
+			Doc:        f.Doc.Text(),
+			Directives: methodDirectives(f.Doc),
+		})
+	}
 	return result, nil
 }
+
+// methodDirectives returns the //gokit: directives of the method doc comment.
+func methodDirectives(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var directives []string
+	for _, c := range doc.List {
+		if strings.HasPrefix(c.Text, "//gokit:") {
+			directives = append(directives, c.Text)
+		}
+	}
+	return directives
+}
+
+// directive returns options of the //gokit:service directive of the doc comment, nil if it has none.
+func directive(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	for _, c := range doc.List {
+		if c.Text == Directive || strings.HasPrefix(c.Text, Directive+" ") {
+			return append([]string{}, strings.Fields(c.Text[len(Directive):])...)
+		}
+	}
+	return nil
+}
@@ -2,12 +2,103 @@ package utils
 
 import (
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// LcFirst lower cases the first letter of v.
 func LcFirst(v string) string {
-	return strings.ToLower(v[:1]) + v[1:]
+	if v == "" {
+		return v
+	}
+	r, n := utf8.DecodeRuneInString(v)
+	return string(unicode.ToLower(r)) + v[n:]
 }
 
+// UcFirst upper cases the first letter of v.
 func UcFirst(v string) string {
-	return strings.ToUpper(v[:1]) + v[1:]
+	if v == "" {
+		return v
+	}
+	r, n := utf8.DecodeRuneInString(v)
+	return string(unicode.ToUpper(r)) + v[n:]
+}
+
+// Words splits a Go identifier into words, keeping initialisms and their plurals together,
+// e.g. "GetUserID" -> ["Get", "User", "ID"], "URLsByID" -> ["URLs", "By", "ID"].
+func Words(v string) []string {
+	var words []string
+	runes := []rune(v)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		switch {
+		case cur == '_' || cur == '-':
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+		case unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			words = append(words, string(runes[start:i]))
+			start = i
+		case unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) &&
+			!isPlural(runes, i+1):
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) && runes[start] != '_' && runes[start] != '-' {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// isPlural reports whether runes[i] is the plural s ending an upper case run, e.g. "IDs".
+func isPlural(runes []rune, i int) bool {
+	return runes[i] == 's' && (i+1 == len(runes) || !unicode.IsLower(runes[i+1]))
+}
+
+// KebabCase converts an identifier to kebab case, e.g. "WithoutParams" -> "without-params".
+func KebabCase(v string) string {
+	return strings.ToLower(strings.Join(Words(v), "-"))
+}
+
+// SnakeCase converts an identifier to snake case, e.g. "WithoutParams" -> "without_params".
+func SnakeCase(v string) string {
+	return strings.ToLower(strings.Join(Words(v), "_"))
+}
+
+// PackageName guesses the package name of an import path by its last element,
+// version suffixes and go- prefixes are dropped, e.g. "gopkg.in/yaml.v2" -> "yaml",
+// "github.com/go-chi/chi/v5" -> "chi", "github.com/openzipkin/zipkin-go" -> "zipkin".
+func PackageName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 && isMajorVersion(name[i+1:]) {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	name = strings.TrimSuffix(name, ".go")
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func isMajorVersion(v string) bool {
+	if len(v) < 2 || v[0] != 'v' {
+		return false
+	}
+	for _, r := range v[1:] {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLcFirst(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"ID", "iD"},
+		{"Say", "say"},
+		{"say", "say"},
+		{"Émile", "émile"},
+	}
+	for _, tc := range cases {
+		if got := LcFirst(tc.in); got != tc.want {
+			t.Errorf("LcFirst(%q): got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestUcFirst(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"id", "Id"},
+		{"say", "Say"},
+		{"Say", "Say"},
+		{"émile", "Émile"},
+	}
+	for _, tc := range cases {
+		if got := UcFirst(tc.in); got != tc.want {
+			t.Errorf("UcFirst(%q): got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"ID", []string{"ID"}},
+		{"IDs", []string{"IDs"}},
+		{"URL", []string{"URL"}},
+		{"URLs", []string{"URLs"}},
+		{"userID", []string{"user", "ID"}},
+		{"userIDs", []string{"user", "IDs"}},
+		{"URLsByID", []string{"URLs", "By", "ID"}},
+		{"GetUserID", []string{"Get", "User", "ID"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"WithoutParams", []string{"Without", "Params"}},
+		{"url1", []string{"url1"}},
+		{"user_name", []string{"user", "name"}},
+	}
+	for _, tc := range cases {
+		if got := Words(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Words(%q): got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"userIDs", "user_ids"},
+		{"HTTPServer", "http_server"},
+		{"WithoutParams", "without_params"},
+	}
+	for _, tc := range cases {
+		if got := SnakeCase(tc.in); got != tc.want {
+			t.Errorf("SnakeCase(%q): got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -1,13 +1,54 @@
+// Code generated by gokitgen. DO NOT EDIT.
+
 package helloservice
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
 )
 
-type errorer interface {
-	Error() error
+// Failure returns the error of a failed endpoint call: the endpoint error or the business error
+// of the response, endpoint middlewares use it to recognise failures of the service methods whatever the error strategy.
+func Failure(response interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+	if f, ok := response.(endpoint.Failer); ok {
+		return f.Failed()
+	}
+	return nil
+}
+
+// LoggingMiddleware returns an endpoint middleware logging the calls with their failures, business errors
+// kept in the responses included, e.g. LoggingMiddleware(log.With(logger, "method", "Say")).
+func LoggingMiddleware(logger log.Logger) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				logger.Log("err", Failure(response, err), "took", time.Since(begin))
+			}(time.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+// InstrumentingMiddleware returns an endpoint middleware observing the durations of the calls in seconds
+// labelled by success, calls failed with business errors kept in the responses are not successful.
+func InstrumentingMiddleware(duration metrics.Histogram) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				success := Failure(response, err) == nil
+				duration.With("success", fmt.Sprint(success)).Observe(time.Since(begin).Seconds())
+			}(time.Now())
+			return next(ctx, request)
+		}
+	}
 }
 
 // Set collects all of the endpoints that compose an Service service.
@@ -18,18 +59,28 @@ type set struct {
 }
 
 // Say implemented interface.
-func (s set) Say(name string) (Message, error) {
-	panic("endpoint not implemented Say")
+func (s set) Say(name string) (message Message, err error) {
+	response, err := s.SayEndpoint(context.Background(), sayRequest{Name: name})
+	if err != nil {
+		return
+	}
+	resp := response.(sayResponse)
+	return resp.Message, resp.Err
 }
 
 // WithoutParams implemented interface.
-func (s set) WithoutParams() error {
-	panic("endpoint not implemented WithoutParams")
+func (s set) WithoutParams() (err error) {
+	response, err := s.WithoutParamsEndpoint(context.Background(), nil)
+	if err != nil {
+		return
+	}
+	resp := response.(withoutParamsResponse)
+	return resp.Err
 }
 
 // WithoutAll implemented interface.
 func (s set) WithoutAll() {
-	panic("endpoint not implemented WithoutAll")
+	s.WithoutAllEndpoint(context.Background(), nil)
 }
 
 func makeSayEndpoint(s Service) endpoint.Endpoint {
@@ -65,13 +116,15 @@ type sayRequest struct {
 
 type sayResponse struct {
 	Message Message
-	Err     error
+	Err     error `json:"-"`
 }
 
-func (r sayResponse) Error() error { return r.Err }
+// Failed implements endpoint.Failer.
+func (r sayResponse) Failed() error { return r.Err }
 
 type withoutParamsResponse struct {
-	Err error
+	Err error `json:"-"`
 }
 
-func (r withoutParamsResponse) Error() error { return r.Err }
+// Failed implements endpoint.Failer.
+func (r withoutParamsResponse) Failed() error { return r.Err }
@@ -0,0 +1,239 @@
+// Code generated by gokitgen. DO NOT EDIT.
+
+package helloservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+)
+
+// ErrBadRequest bad request.
+var ErrBadRequest = errors.New("bad request")
+
+// httpErrors http status codes of the service errors.
+var httpErrors = []struct {
+	err  error
+	code int
+}{
+	{ErrBadRequest, http.StatusBadRequest},
+}
+
+// HTTPHandlerOption option of the HTTP handler.
+type HTTPHandlerOption func(o *httpHandlerOptions)
+
+type httpHandlerOptions struct {
+	opts        []kithttp.ServerOption
+	endpoints   []endpoint.Middleware
+	middlewares []func(http.Handler) http.Handler
+}
+
+// RequestIDHeader header of the request IDs, see WithRequestID.
+const RequestIDHeader = "X-Request-ID"
+
+// httpContextKey keys of the values the handler options put into the request contexts.
+type httpContextKey int
+
+const (
+	requestIDContextKey httpContextKey = iota
+	authTokenContextKey
+)
+
+// WithRequestID puts the ID of the RequestIDHeader header into the request contexts,
+// requests without the header get a random ID, see RequestIDFromContext.
+func WithRequestID() HTTPHandlerOption {
+	return func(o *httpHandlerOptions) {
+		o.opts = append(o.opts, kithttp.ServerBefore(func(ctx context.Context, r *http.Request) context.Context {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" {
+				b := make([]byte, 16)
+				rand.Read(b)
+				id = hex.EncodeToString(b)
+			}
+			return context.WithValue(ctx, requestIDContextKey, id)
+		}))
+	}
+}
+
+// RequestIDFromContext returns the request ID put into the context by WithRequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// WithAuthToken puts the bearer token of the Authorization header into the request contexts,
+// see AuthTokenFromContext.
+func WithAuthToken() HTTPHandlerOption {
+	return func(o *httpHandlerOptions) {
+		o.opts = append(o.opts, kithttp.ServerBefore(func(ctx context.Context, r *http.Request) context.Context {
+			auth := r.Header.Get("Authorization")
+			if len(auth) <= len("Bearer ") || !strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+				return ctx
+			}
+			return context.WithValue(ctx, authTokenContextKey, auth[len("Bearer "):])
+		}))
+	}
+}
+
+// AuthTokenFromContext returns the bearer token put into the context by WithAuthToken.
+func AuthTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(authTokenContextKey).(string)
+	return token
+}
+
+// WithCORS allows cross-origin requests from the origins, "*" allows any origin.
+// Preflight requests are answered with the requested method and headers.
+func WithCORS(origins ...string) HTTPHandlerOption {
+	allowed := map[string]bool{}
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+	return WithHandlerMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || !allowed["*"] && !allowed[origin] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Add("Vary", "Origin")
+			if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+			if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+}
+
+// WithServerOptions go-kit options of the handlers of the endpoints.
+func WithServerOptions(opts ...kithttp.ServerOption) HTTPHandlerOption {
+	return func(o *httpHandlerOptions) {
+		o.opts = append(o.opts, opts...)
+	}
+}
+
+// WithServerEndpointMiddleware middlewares wrapping every endpoint of the handler, the first one is the outermost,
+// e.g. LoggingMiddleware.
+func WithServerEndpointMiddleware(middlewares ...endpoint.Middleware) HTTPHandlerOption {
+	return func(o *httpHandlerOptions) {
+		o.endpoints = append(o.endpoints, middlewares...)
+	}
+}
+
+// WithHandlerMiddleware middlewares wrapping the handler, the first one is the outermost.
+func WithHandlerMiddleware(middlewares ...func(http.Handler) http.Handler) HTTPHandlerOption {
+	return func(o *httpHandlerOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// wrap wraps the endpoint with the endpoint middlewares of the options.
+func (o httpHandlerOptions) wrap(e endpoint.Endpoint) endpoint.Endpoint {
+	if len(o.endpoints) == 0 {
+		return e
+	}
+	return endpoint.Chain(o.endpoints[0], o.endpoints[1:]...)(e)
+}
+
+// handler wraps the handler with the middlewares.
+func (o httpHandlerOptions) handler(h http.Handler) http.Handler {
+	for i := len(o.middlewares) - 1; i >= 0; i-- {
+		h = o.middlewares[i](h)
+	}
+	return h
+}
+
+// NewHTTPHandler returns an HTTP handler.
+func NewHTTPHandler(svc Service, options ...HTTPHandlerOption) http.Handler {
+	var o httpHandlerOptions
+	for _, option := range options {
+		option(&o)
+	}
+	opts := append([]kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(errorHTTPEncoder),
+	}, o.opts...)
+
+	sayHandler := kithttp.NewServer(
+		o.wrap(makeSayEndpoint(svc)),
+		decodeHTTPSayRequest,
+		encodeHTTPGenericResponse(http.StatusOK),
+		opts...,
+	)
+
+	withoutParamsHandler := kithttp.NewServer(
+		o.wrap(makeWithoutParamsEndpoint(svc)),
+		decodeHTTPWithoutParamsRequest,
+		encodeHTTPWithoutParamsResponse,
+		opts...,
+	)
+
+	withoutAllHandler := kithttp.NewServer(
+		o.wrap(makeWithoutAllEndpoint(svc)),
+		decodeHTTPWithoutAllRequest,
+		encodeHTTPWithoutAllResponse,
+		opts...,
+	)
+
+	r := mux.NewRouter().UseEncodedPath()
+	r.Methods("POST").Path("/say").Handler(sayHandler)
+	r.Methods("POST").Path("/without-params").Handler(withoutParamsHandler)
+	r.Methods("POST").Path("/without-all").Handler(withoutAllHandler)
+
+	return o.handler(r)
+}
+
+func decodeHTTPSayRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req sayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, ErrBadRequest
+	}
+	return req, nil
+}
+
+// encodeHTTPGenericResponse returns an encoder writing responses as JSON with the status code,
+// responses of 204 No Content have no body.
+func encodeHTTPGenericResponse(code int) kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		if f, ok := response.(endpoint.Failer); ok && f.Failed() != nil {
+			errorHTTPEncoder(ctx, f.Failed(), w)
+			return nil
+		}
+		if code == http.StatusNoContent {
+			w.WriteHeader(code)
+			return nil
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		return json.NewEncoder(w).Encode(response)
+	}
+}
+
+func errorHTTPEncoder(ctx context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	code := http.StatusInternalServerError
+	for _, e := range httpErrors {
+		if e.err == err {
+			code = e.code
+			break
+		}
+	}
+	w.WriteHeader(code)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": err.Error(),
+	})
+}